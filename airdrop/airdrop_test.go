@@ -0,0 +1,187 @@
+package airdrop_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/airdrop"
+	"github.com/yellowbackground/holders/testdata"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := map[string]struct {
+		Budget  uint64
+		Weights map[string]uint64
+		Want    map[string]uint64
+	}{
+		"exact split": {
+			Budget:  100,
+			Weights: map[string]uint64{"A": 1, "B": 3},
+			Want:    map[string]uint64{"A": 25, "B": 75},
+		},
+		"largest remainders get the leftover units": {
+			Budget:  10,
+			Weights: map[string]uint64{"A": 1, "B": 1, "C": 1},
+			Want:    map[string]uint64{"A": 4, "B": 3, "C": 3},
+		},
+		"remainders decide before addresses": {
+			Budget:  100,
+			Weights: map[string]uint64{"A": 1, "B": 2, "C": 4},
+			// exact shares are 14.29, 28.57 and 57.14
+			Want: map[string]uint64{"A": 14, "B": 29, "C": 57},
+		},
+		"large amounts don't overflow": {
+			Budget:  1 << 62,
+			Weights: map[string]uint64{"A": 1 << 62, "B": 1 << 62},
+			Want:    map[string]uint64{"A": 1 << 61, "B": 1 << 61},
+		},
+		"no weight": {
+			Budget:  100,
+			Weights: map[string]uint64{},
+			Want:    map[string]uint64{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := airdrop.Allocate(test.Budget, test.Weights)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.Want, got)
+		})
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	holdings := map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: "A", Amount: 1, AssetID: 1},
+			{Address: "A", Amount: 1, AssetID: 2},
+			{Address: "B", Amount: 1, AssetID: 3},
+			{Address: "C", Amount: 1, AssetID: 4},
+			{Address: "CREATOR", Amount: 1, AssetID: 5},
+		},
+		"Best Frens": {
+			{Address: "B", Amount: 1, AssetID: 6},
+			{Address: "D", Amount: 1, AssetID: 7},
+		},
+		"Ignored": {{Address: "E", Amount: 1, AssetID: 8}},
+	}
+	collections := []holders.WeightedCollection{
+		{Collection: holders.Collection{Name: "Mostly Frens"}, Weight: 1},
+		{Collection: holders.Collection{Name: "Best Frens"}, Weight: 4},
+	}
+	// weights are A 2, B 5, C 1 and D 4
+	optedIn := map[string]bool{"A": true, "B": true, "C": true}
+
+	tests := map[string]struct {
+		Config airdrop.Config
+		Want   airdrop.Plan
+	}{
+		"holds back the share of recipients not opted in": {
+			Config: airdrop.Config{AssetID: 99, Budget: 1200, Collections: collections, ExcludedAddresses: []string{"CREATOR"}},
+			Want: airdrop.Plan{
+				AssetID:   99,
+				Budget:    1200,
+				Allocated: 800,
+				Allocations: []airdrop.Allocation{
+					{Address: "B", Weight: 5, Amount: 500},
+					{Address: "A", Weight: 2, Amount: 200},
+					{Address: "C", Weight: 1, Amount: 100},
+				},
+				Rejections: []airdrop.Rejection{{Address: "D", Weight: 4, Amount: 400, Reason: airdrop.ReasonNotOptedIn}},
+			},
+		},
+		"redistributes the share of recipients not opted in": {
+			Config: airdrop.Config{AssetID: 99, Budget: 800, Collections: collections, ExcludedAddresses: []string{"CREATOR"}, Redistribute: true},
+			Want: airdrop.Plan{
+				AssetID:   99,
+				Budget:    800,
+				Allocated: 800,
+				Allocations: []airdrop.Allocation{
+					{Address: "B", Weight: 5, Amount: 500},
+					{Address: "A", Weight: 2, Amount: 200},
+					{Address: "C", Weight: 1, Amount: 100},
+				},
+				Rejections: []airdrop.Rejection{{Address: "D", Weight: 4, Amount: 267, Reason: airdrop.ReasonNotOptedIn}},
+			},
+		},
+		"drops dust and shares it": {
+			Config: airdrop.Config{AssetID: 99, Budget: 800, Collections: collections, ExcludedAddresses: []string{"CREATOR"}, Redistribute: true, MinimumAmount: 150},
+			Want: airdrop.Plan{
+				AssetID:   99,
+				Budget:    800,
+				Allocated: 800,
+				Allocations: []airdrop.Allocation{
+					{Address: "B", Weight: 5, Amount: 571},
+					{Address: "A", Weight: 2, Amount: 229},
+				},
+				Rejections: []airdrop.Rejection{
+					{Address: "C", Weight: 1, Amount: 100, Reason: airdrop.ReasonDust},
+					{Address: "D", Weight: 4, Amount: 267, Reason: airdrop.ReasonNotOptedIn},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &testdata.FakeCollectionClient{OptedIn: optedIn}
+
+			got, err := airdrop.NewPlan(context.Background(), client, holdings, test.Config)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.Want, got)
+			assert.Equal(t, 4, client.CallCount("IsOptedIn"))
+		})
+	}
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	client := &testdata.FakeCollectionClient{}
+
+	_, err := airdrop.NewPlan(context.Background(), client, nil, airdrop.Config{})
+	assert.EqualError(t, err, "budget must be greater than 0")
+
+	_, err = airdrop.NewPlan(context.Background(), client, nil, airdrop.Config{Budget: 1})
+	assert.EqualError(t, err, "no holders to allocate to")
+}
+
+func TestBuildTransactions(t *testing.T) {
+	plan := airdrop.Plan{AssetID: 99}
+	for i := 0; i < 20; i++ {
+		plan.Allocations = append(plan.Allocations, airdrop.Allocation{Address: testdata.TestAccount2Address, Amount: uint64(i + 1)})
+	}
+	params := types.SuggestedParams{Fee: 0, FirstRoundValid: 1000, LastRoundValid: 2000, GenesisID: "testnet-v1.0", GenesisHash: make([]byte, 32), MinFee: 1000}
+
+	groups, err := airdrop.BuildTransactions(plan, testdata.TestAccount1Address, params, []byte("airdrop"))
+
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Len(t, groups[0], 16)
+	assert.Len(t, groups[1], 4)
+	assert.NotEqual(t, groups[0][0].Group, groups[1][0].Group)
+	for i, txn := range groups[1] {
+		assert.Equal(t, groups[1][0].Group, txn.Group)
+		assert.Equal(t, uint64(17+i), txn.AssetAmount)
+		assert.Equal(t, types.AssetIndex(99), txn.XferAsset)
+		assert.Equal(t, testdata.TestAccount2Address, txn.AssetReceiver.String())
+	}
+
+	var buffer bytes.Buffer
+	assert.NoError(t, airdrop.WriteUnsignedTransactions(&buffer, groups))
+	decoder := msgpack.NewDecoder(&buffer)
+	var decoded []types.Transaction
+	for {
+		var stx types.SignedTxn
+		if err := decoder.Decode(&stx); err != nil {
+			break
+		}
+		decoded = append(decoded, stx.Txn)
+	}
+	assert.Equal(t, append(groups[0], groups[1]...), decoded)
+}
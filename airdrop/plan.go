@@ -0,0 +1,250 @@
+// Package airdrop plans airdrops of a reward asset in proportion to weighted holdings
+// and builds the unsigned transactions that send them.
+package airdrop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+const (
+	ReasonNotOptedIn = "not opted in to the reward asset"
+	ReasonDust       = "allocation below the minimum amount"
+)
+
+type Config struct {
+	// AssetID is the reward asset.
+	AssetID uint64
+	// Budget is the amount to distribute, in base units of the reward asset.
+	Budget uint64
+	// Collections weigh each holding the same way raffle tickets are weighed. Holdings of other collections are ignored.
+	Collections []holders.WeightedCollection
+	// MinimumAmount drops allocations below this many base units and shares them among the other recipients.
+	MinimumAmount     uint64
+	ExcludedAddresses []string
+	// Redistribute shares the allocations of recipients who are not opted in among the others.
+	// They are left unallocated otherwise, so they can be sent once the recipients opt in.
+	Redistribute bool
+	// Concurrency is the number of opt-in checks made at once. Defaults to 1.
+	Concurrency int
+}
+
+type Allocation struct {
+	Address string
+	Weight  uint64
+	Amount  uint64
+}
+
+// Rejection is a holder who will not receive the asset, with the amount they would have received.
+type Rejection struct {
+	Address string
+	Weight  uint64
+	Amount  uint64
+	Reason  string
+}
+
+type Plan struct {
+	AssetID uint64
+	Budget  uint64
+	// Allocated is the sum of the allocations. Budget - Allocated is left unspent.
+	Allocated   uint64
+	Allocations []Allocation
+	Rejections  []Rejection
+}
+
+// NewPlan allocates the budget in proportion to the weighted holdings of each address
+// and checks that every recipient is opted in to the reward asset.
+func NewPlan(ctx context.Context, client holders.AssetOptInClient, holdingsByCollection map[string][]holders.AssetHolding, config Config) (Plan, error) {
+	if config.Budget == 0 {
+		return Plan{}, errors.New("budget must be greater than 0")
+	}
+
+	weights, err := holderWeights(holdingsByCollection, config)
+	if err != nil {
+		return Plan{}, err
+	}
+	if len(weights) == 0 {
+		return Plan{}, errors.New("no holders to allocate to")
+	}
+
+	optedIn, err := checkOptIns(ctx, client, weights, config)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{AssetID: config.AssetID, Budget: config.Budget}
+	initial, err := Allocate(config.Budget, weights)
+	if err != nil {
+		return Plan{}, err
+	}
+	for address := range weights {
+		if !optedIn[address] {
+			plan.Rejections = append(plan.Rejections, Rejection{Address: address, Weight: weights[address], Amount: initial[address], Reason: ReasonNotOptedIn})
+			if config.Redistribute {
+				delete(weights, address)
+			}
+		}
+	}
+
+	// dropping dust raises everyone else's share, which can lift or leave others below the minimum, so repeat until stable
+	var amounts map[string]uint64
+	for {
+		if amounts, err = Allocate(config.Budget, weights); err != nil {
+			return Plan{}, err
+		}
+		dropped := false
+		for address, amount := range amounts {
+			if amount < config.MinimumAmount {
+				if optedIn[address] {
+					plan.Rejections = append(plan.Rejections, Rejection{Address: address, Weight: weights[address], Amount: amount, Reason: ReasonDust})
+				}
+				delete(weights, address)
+				dropped = true
+			}
+		}
+		if !dropped {
+			break
+		}
+	}
+
+	for i, rejection := range plan.Rejections {
+		if amount, found := amounts[rejection.Address]; found && rejection.Reason == ReasonNotOptedIn {
+			// report the amount actually held back for them
+			plan.Rejections[i].Amount = amount
+		}
+	}
+	for address, amount := range amounts {
+		if !optedIn[address] {
+			continue
+		}
+		plan.Allocations = append(plan.Allocations, Allocation{Address: address, Weight: weights[address], Amount: amount})
+		plan.Allocated += amount
+	}
+
+	sort.Slice(plan.Allocations, func(i, j int) bool {
+		if plan.Allocations[i].Amount != plan.Allocations[j].Amount {
+			return plan.Allocations[i].Amount > plan.Allocations[j].Amount
+		}
+		return plan.Allocations[i].Address < plan.Allocations[j].Address
+	})
+	sort.Slice(plan.Rejections, func(i, j int) bool {
+		return plan.Rejections[i].Address < plan.Rejections[j].Address
+	})
+	return plan, nil
+}
+
+// Allocate splits budget in proportion to weights using the largest remainder method,
+// so the amounts always add up to the whole budget. Ties go to the lowest address.
+func Allocate(budget uint64, weights map[string]uint64) (map[string]uint64, error) {
+	var totalWeight uint64
+	for _, weight := range weights {
+		var carry uint64
+		totalWeight, carry = bits.Add64(totalWeight, weight, 0)
+		if carry != 0 {
+			return nil, errors.New("total weight overflows uint64")
+		}
+	}
+
+	amounts := make(map[string]uint64, len(weights))
+	if totalWeight == 0 {
+		return amounts, nil
+	}
+
+	type remainder struct {
+		address string
+		value   uint64
+	}
+	remainders := make([]remainder, 0, len(weights))
+	remaining := budget
+	for address, weight := range weights {
+		hi, lo := bits.Mul64(budget, weight)
+		quotient, rem := bits.Div64(hi, lo, totalWeight)
+		amounts[address] = quotient
+		remaining -= quotient
+		remainders = append(remainders, remainder{address: address, value: rem})
+	}
+
+	sort.Slice(remainders, func(i, j int) bool {
+		if remainders[i].value != remainders[j].value {
+			return remainders[i].value > remainders[j].value
+		}
+		return remainders[i].address < remainders[j].address
+	})
+	// the floors fall short of the budget by less than one unit per recipient
+	for i := uint64(0); i < remaining; i++ {
+		amounts[remainders[i].address]++
+	}
+	return amounts, nil
+}
+
+func holderWeights(holdingsByCollection map[string][]holders.AssetHolding, config Config) (map[string]uint64, error) {
+	excluded := make(map[string]bool, len(config.ExcludedAddresses))
+	for _, address := range config.ExcludedAddresses {
+		excluded[address] = true
+	}
+
+	weights := make(map[string]uint64)
+	for _, weightedCollection := range config.Collections {
+		for _, holding := range holdingsByCollection[weightedCollection.Collection.Name] {
+			if excluded[holding.Address] {
+				continue
+			}
+			holdingWeight, err := weightedCollection.HoldingWeight(holding)
+			if err != nil {
+				return nil, err
+			}
+			weight, carry := bits.Add64(weights[holding.Address], holdingWeight, 0)
+			if carry != 0 {
+				return nil, fmt.Errorf("weight of %s overflows uint64", holding.Address)
+			}
+			if weight > 0 {
+				weights[holding.Address] = weight
+			}
+		}
+	}
+	return weights, nil
+}
+
+func checkOptIns(ctx context.Context, client holders.AssetOptInClient, weights map[string]uint64, config Config) (map[string]bool, error) {
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	optedIn := make(map[string]bool, len(weights))
+	mutex := &sync.Mutex{}
+	sem := make(chan bool, concurrency)
+	errChan := make(chan error, len(weights))
+	wg := &sync.WaitGroup{}
+
+	for address := range weights {
+		wg.Add(1)
+		sem <- true
+		go func(address string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			isOptedIn, err := client.IsOptedIn(ctx, address, config.AssetID)
+			if err != nil {
+				errChan <- fmt.Errorf("checking opt-in of %s: %w", address, err)
+				return
+			}
+			mutex.Lock()
+			optedIn[address] = isOptedIn
+			mutex.Unlock()
+		}(address)
+	}
+
+	wg.Wait()
+	close(errChan)
+	for err := range errChan {
+		return nil, err
+	}
+	return optedIn, nil
+}
@@ -0,0 +1,58 @@
+package airdrop
+
+import (
+	"errors"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"io"
+)
+
+// MaxGroupSize is the most transactions Algorand accepts in one atomic group.
+const MaxGroupSize = 16
+
+// BuildTransactions creates an asset transfer from sender for every allocation, in atomic groups of up to
+// MaxGroupSize. A group fails as a whole if any of its recipients opts out before it is sent.
+func BuildTransactions(plan Plan, sender string, params types.SuggestedParams, note []byte) ([][]types.Transaction, error) {
+	if len(plan.Allocations) == 0 {
+		return nil, errors.New("plan has no allocations")
+	}
+
+	var groups [][]types.Transaction
+	for start := 0; start < len(plan.Allocations); start += MaxGroupSize {
+		end := min(start+MaxGroupSize, len(plan.Allocations))
+
+		group := make([]types.Transaction, 0, end-start)
+		for _, allocation := range plan.Allocations[start:end] {
+			txn, err := future.MakeAssetTransferTxn(sender, allocation.Address, allocation.Amount, note, params, "", plan.AssetID)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, txn)
+		}
+
+		groupID, err := crypto.ComputeGroupID(group)
+		if err != nil {
+			return nil, err
+		}
+		for i := range group {
+			group[i].Group = groupID
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// WriteUnsignedTransactions writes the transactions as msgpack encoded unsigned transactions,
+// the format `goal clerk sign` reads.
+func WriteUnsignedTransactions(w io.Writer, groups [][]types.Transaction) error {
+	for _, group := range groups {
+		for _, txn := range group {
+			if _, err := w.Write(msgpack.Encode(types.SignedTxn{Txn: txn})); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
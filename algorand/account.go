@@ -0,0 +1,65 @@
+package algorand
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/yellowbackground/holders"
+	"time"
+)
+
+const onlineStatus = "Online"
+
+func NewAccountStateClient(algoD *algod.Client, idxClient *indexer.Client) holders.AccountStateClient {
+	return &collectionClient{
+		algodClient:   algoD,
+		indexerClient: idxClient,
+	}
+}
+
+// GetAccountState reads the current balance, assets and status from algod and the account history from the indexer
+func (c collectionClient) GetAccountState(ctx context.Context, address string) (holders.AccountState, error) {
+	accountInfo, err := c.algodClient.AccountInformation(address).Do(ctx)
+	if err != nil {
+		return holders.AccountState{}, err
+	}
+
+	_, indexedAccount, err := c.indexerClient.LookupAccountByID(address).IncludeAll(true).Do(ctx)
+	if err != nil {
+		return holders.AccountState{}, err
+	}
+
+	state := holders.AccountState{
+		Address:        address,
+		Balance:        accountInfo.Amount,
+		CreatedAtRound: indexedAccount.CreatedAtRound,
+		OptedInAssets:  uint64(len(accountInfo.Assets)),
+		Participating:  accountInfo.Status == onlineStatus,
+		Closed:         indexedAccount.Deleted,
+	}
+
+	block, err := c.indexerClient.LookupBlock(indexedAccount.CreatedAtRound).HeaderOnly(true).Do(ctx)
+	if err != nil {
+		return holders.AccountState{}, err
+	}
+	state.CreatedAt = time.Unix(int64(block.Timestamp), 0)
+
+	return state, nil
+}
+
+func NewAuthAddressClient(algoD *algod.Client) holders.AuthAddressClient {
+	return &collectionClient{
+		algodClient: algoD,
+	}
+}
+
+func (c collectionClient) AuthAddress(ctx context.Context, address string) (string, error) {
+	accountInfo, err := c.algodClient.AccountInformation(address).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if accountInfo.AuthAddr == "" {
+		return address, nil
+	}
+	return accountInfo.AuthAddr, nil
+}
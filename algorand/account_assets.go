@@ -0,0 +1,65 @@
+package algorand
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/yellowbackground/holders"
+)
+
+func NewAccountAssetsClient(algoD *algod.Client, idxClient *indexer.Client) holders.AccountAssetsClient {
+	return &collectionClient{
+		algodClient:   algoD,
+		indexerClient: idxClient,
+	}
+}
+
+// GetAccountAssets returns the non-zero asset balances of an account using the indexer's per-account lookup
+func (c collectionClient) GetAccountAssets(ctx context.Context, address string) ([]holders.AccountAsset, error) {
+	var accountAssets []holders.AccountAsset
+	nextToken := ""
+	for {
+		res, err := c.indexerClient.LookupAccountAssets(address).
+			Limit(1000).
+			Next(nextToken).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range res.Assets {
+			if asset.Amount == 0 || asset.Deleted {
+				continue
+			}
+			accountAssets = append(accountAssets, holders.AccountAsset{
+				AssetID: asset.AssetId,
+				Amount:  asset.Amount,
+			})
+		}
+
+		if res.NextToken == "" {
+			break
+		}
+		nextToken = res.NextToken
+	}
+
+	return accountAssets, nil
+}
+
+func NewAssetOptInClient(algoD *algod.Client) holders.AssetOptInClient {
+	return &collectionClient{
+		algodClient: algoD,
+	}
+}
+
+// IsOptedIn asks algod for the account's holding of the asset, which is missing when it has not opted in
+func (c collectionClient) IsOptedIn(ctx context.Context, address string, assetID uint64) (bool, error) {
+	_, err := c.algodClient.AccountAssetInformation(address, assetID).Do(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
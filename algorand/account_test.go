@@ -0,0 +1,311 @@
+package algorand_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+	raffle "github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/algorand"
+	"github.com/yellowbackground/holders/testdata"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetAccountState(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewAccountStateClient(nodeCli, idxCli)
+
+	algodAccountMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount2Address).
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{
+		  "address": "%s",
+		  "amount": 5000000,
+		  "status": "Online",
+		  "assets": [{"asset-id": 1, "amount": 1}, {"asset-id": 2, "amount": 0}]
+		}`, testdata.TestAccount2Address)).
+		End()
+	indexerAccountMock := apitest.NewMock().
+		Get("http://localhost:9000/v2/accounts/" + testdata.TestAccount2Address).
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{
+		  "current-round": 200,
+		  "account": {
+			"address": "%s",
+			"amount": 5000000,
+			"created-at-round": 100,
+			"deleted": false
+		  }
+		}`, testdata.TestAccount2Address)).
+		End()
+	blockMock := apitest.NewMock().
+		Get("http://localhost:9000/v2/blocks/100").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"round": 100, "timestamp": 1700000000}`).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(algodAccountMock, indexerAccountMock, blockMock).End()
+	defer resetTransport()
+
+	state, err := underTest.GetAccountState(context.Background(), testdata.TestAccount2Address)
+
+	assert.NoError(t, err)
+	assert.Equal(t, raffle.AccountState{
+		Address:        testdata.TestAccount2Address,
+		Balance:        5000000,
+		CreatedAtRound: 100,
+		CreatedAt:      time.Unix(1700000000, 0),
+		OptedInAssets:  2,
+		Participating:  true,
+	}, state)
+
+	result := raffle.ApplyEligibilityRules(state, []raffle.EligibilityRule{
+		raffle.MinimumAlgoBalance(5 * 1000000),
+		raffle.MinimumAccountAge(30 * 24 * time.Hour),
+		raffle.MinimumOptedInAssets(3),
+		raffle.RequireNotClosed(),
+	})
+
+	assert.False(t, result.Eligible)
+	assert.Equal(t, raffle.RuleResult{Rule: "MinimumOptedInAssets", Passed: false, Reason: "opted into 2 assets, minimum 3"}, result.Rules[2])
+	assert.Equal(t, raffle.RuleResult{Rule: "MinimumAlgoBalance", Passed: true, Reason: "balance 5.000000 ALGO, minimum 5.000000 ALGO"}, result.Rules[0])
+}
+
+func TestGetAccountAssets(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewAccountAssetsClient(nodeCli, idxCli)
+
+	accountAssetsMock := apitest.NewMock().
+		Get("http://localhost:9000/v2/accounts/" + testdata.TestAccount2Address + "/assets").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{
+		  "current-round": 200,
+		  "assets": [
+			{"asset-id": 1, "amount": 1},
+			{"asset-id": 2, "amount": 0},
+			{"asset-id": 3, "amount": 1, "deleted": true}
+		  ]
+		}`).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(accountAssetsMock).End()
+	defer resetTransport()
+
+	accountAssets, err := underTest.GetAccountAssets(context.Background(), testdata.TestAccount2Address)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []raffle.AccountAsset{{AssetID: 1, Amount: 1}}, accountAssets)
+}
+
+func TestIsOptedIn(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	underTest := algorand.NewAssetOptInClient(nodeCli)
+
+	optedInMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount1Address + "/assets/7").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"round": 200, "asset-holding": {"asset-id": 7, "amount": 0}}`).
+		End()
+	notOptedInMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount2Address + "/assets/7").
+		RespondWith().
+		Status(http.StatusNotFound).
+		JSON(`{"message": "account asset info not found"}`).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(optedInMock, notOptedInMock).End()
+	defer resetTransport()
+
+	optedIn, err := underTest.IsOptedIn(context.Background(), testdata.TestAccount1Address, 7)
+	assert.NoError(t, err)
+	assert.True(t, optedIn)
+
+	optedIn, err = underTest.IsOptedIn(context.Background(), testdata.TestAccount2Address, 7)
+	assert.NoError(t, err)
+	assert.False(t, optedIn)
+}
+
+func TestTransactionSenderRounds(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewTransactionSender(nodeCli, idxCli)
+
+	statusMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/status").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"last-round": 1000}`).
+		End()
+	healthMock := apitest.NewMock().
+		Get("http://localhost:9000/health").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"round": 990}`).
+		End()
+	pendingMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/transactions/pending/TXID").
+		RespondWith().
+		Status(http.StatusNotFound).
+		JSON(`{"message": "txn not found"}`).
+		End()
+	lookupMock := apitest.NewMock().
+		Get("http://localhost:9000/v2/transactions/TXID").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"current-round": 990, "transaction": {"id": "TXID", "confirmed-round": 980}}`).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(statusMock, healthMock, pendingMock, lookupMock).End()
+	defer resetTransport()
+
+	round, err := underTest.CurrentRound(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(990), round)
+
+	confirmedRound, err := underTest.ConfirmedRound(context.Background(), "TXID")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(980), confirmedRound)
+}
+
+func TestWaitForBlock(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	underTest := algorand.NewBlockClient(nodeCli)
+
+	statusMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/status/wait-for-block-after/99").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(`{"last-round": 100}`).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(statusMock).End()
+	defer resetTransport()
+
+	assert.NoError(t, underTest.WaitForBlock(context.Background(), 100))
+}
+
+func TestAuthAddress(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	underTest := algorand.NewAuthAddressClient(nodeCli)
+
+	rekeyedMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount1Address).
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{"address": "%s", "auth-addr": "%s"}`, testdata.TestAccount1Address, testdata.TestAccount2Address)).
+		End()
+	notRekeyedMock := apitest.NewMock().
+		Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount2Address).
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{"address": "%s"}`, testdata.TestAccount2Address)).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(rekeyedMock, notRekeyedMock).End()
+	defer resetTransport()
+
+	authAddress, err := underTest.AuthAddress(context.Background(), testdata.TestAccount1Address)
+	assert.NoError(t, err)
+	assert.Equal(t, testdata.TestAccount2Address, authAddress)
+
+	authAddress, err = underTest.AuthAddress(context.Background(), testdata.TestAccount2Address)
+	assert.NoError(t, err)
+	assert.Equal(t, testdata.TestAccount2Address, authAddress)
+}
+
+func TestNotFoundResponses(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	optIns := algorand.NewAssetOptInClient(nodeCli)
+	sender := algorand.NewTransactionSender(nodeCli, idxCli)
+
+	tests := map[string]struct {
+		GotStatus int
+		WantErr   bool
+	}{
+		"not found": {
+			GotStatus: http.StatusNotFound,
+		},
+		"server error": {
+			GotStatus: http.StatusInternalServerError,
+			WantErr:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			optInMock := apitest.NewMock().
+				Get("http://localhost:8000/v2/accounts/" + testdata.TestAccount2Address + "/assets/7").
+				RespondWith().
+				Status(test.GotStatus).
+				JSON(`{"message": "failed"}`).
+				End()
+			pendingMock := apitest.NewMock().
+				Get("http://localhost:8000/v2/transactions/pending/TXID").
+				RespondWith().
+				Status(http.StatusNotFound).
+				JSON(`{"message": "txn not found"}`).
+				End()
+			lookupMock := apitest.NewMock().
+				Get("http://localhost:9000/v2/transactions/TXID").
+				RespondWith().
+				Status(test.GotStatus).
+				JSON(`{"message": "failed"}`).
+				End()
+			resetTransport := apitest.NewStandaloneMocks(optInMock, pendingMock, lookupMock).End()
+			defer resetTransport()
+
+			optedIn, err := optIns.IsOptedIn(context.Background(), testdata.TestAccount2Address, 7)
+			assert.Equal(t, test.WantErr, err != nil, err)
+			assert.False(t, optedIn)
+
+			confirmedRound, err := sender.ConfirmedRound(context.Background(), "TXID")
+			assert.Equal(t, test.WantErr, err != nil, err)
+			assert.Zero(t, confirmedRound)
+		})
+	}
+}
+
+func TestGetAssetBalances(t *testing.T) {
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewAssetBalancesClient(idxCli)
+
+	page := func(next string, round uint64, nextToken string, address string) *apitest.Mock {
+		request := apitest.NewMock().Get("http://localhost:9000/v2/assets/1/balances")
+		if next == "" {
+			request = request.QueryNotPresent("next")
+		} else {
+			request = request.Query("next", next)
+		}
+		return request.
+			RespondWith().
+			Status(http.StatusOK).
+			JSON(fmt.Sprintf(`{
+			  "current-round": %d,
+			  "next-token": "%s",
+			  "balances": [
+				{"address": "%s", "amount": 1},
+				{"address": "EMPTY", "amount": 0}
+			  ]
+			}`, round, nextToken, address)).
+			End()
+	}
+	resetTransport := apitest.NewStandaloneMocks(
+		// a block lands between the pages of the first read, so the balances are read again
+		page("", 100, "a", testdata.TestAccount1Address),
+		page("a", 101, "", testdata.TestAccount2Address),
+		page("", 101, "b", testdata.TestAccount1Address),
+		page("b", 101, "", testdata.TestAccount2Address),
+	).End()
+	defer resetTransport()
+
+	balances, round, err := underTest.GetAssetBalances(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(101), round)
+	assert.Equal(t, map[string]uint64{testdata.TestAccount1Address: 1, testdata.TestAccount2Address: 1}, balances)
+}
@@ -0,0 +1,86 @@
+package algorand
+
+import (
+	"context"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+)
+
+func NewBlockClient(algoD *algod.Client) holders.BlockClient {
+	return &collectionClient{
+		algodClient: algoD,
+	}
+}
+
+// WaitForBlock uses status-after-block, which returns when a newer round is available or after a node-side timeout
+func (c collectionClient) WaitForBlock(ctx context.Context, round uint64) error {
+	for {
+		status, err := c.algodClient.StatusAfterBlock(round - 1).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if status.LastRound >= round {
+			return nil
+		}
+	}
+}
+
+func (c collectionClient) Block(ctx context.Context, round uint64) (types.Block, error) {
+	return c.algodClient.Block(round).Do(ctx)
+}
+
+// balancesAttempts is how many times GetAssetBalances reads the pages of an asset before giving up on them agreeing on a round.
+const balancesAttempts = 3
+
+func NewAssetBalancesClient(idxClient *indexer.Client) holders.AssetBalancesClient {
+	return &collectionClient{
+		indexerClient: idxClient,
+	}
+}
+
+// GetAssetBalances pages through the indexer's balances of the asset. Every page must be read at the same
+// current-round, otherwise a transfer between pages could be counted twice or not at all, so it starts over.
+func (c collectionClient) GetAssetBalances(ctx context.Context, assetID uint64) (map[string]uint64, uint64, error) {
+	for attempt := 0; attempt < balancesAttempts; attempt++ {
+		balances, round, consistent, err := c.readAssetBalances(ctx, assetID)
+		if err != nil || consistent {
+			return balances, round, err
+		}
+	}
+	return nil, 0, fmt.Errorf("balances of asset %d changed round while paging %d times", assetID, balancesAttempts)
+}
+
+func (c collectionClient) readAssetBalances(ctx context.Context, assetID uint64) (map[string]uint64, uint64, bool, error) {
+	balances := make(map[string]uint64)
+	var round uint64
+	nextToken := ""
+	for {
+		res, err := c.indexerClient.LookupAssetBalances(assetID).
+			Limit(1000).
+			NextToken(nextToken).
+			Do(ctx)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if nextToken == "" {
+			round = res.CurrentRound
+		} else if res.CurrentRound != round {
+			return nil, 0, false, nil
+		}
+
+		for _, balance := range res.Balances {
+			if balance.Amount > 0 && !balance.Deleted {
+				balances[balance.Address] = balance.Amount
+			}
+		}
+
+		if res.NextToken == "" {
+			break
+		}
+		nextToken = res.NextToken
+	}
+	return balances, round, true, nil
+}
@@ -3,9 +3,12 @@ package algorand
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
 	"github.com/yellowbackground/holders"
+	"net/http"
 	"strings"
 )
 
@@ -84,7 +87,57 @@ func (c collectionClient) getAssetHolder(ctx context.Context, asset holders.Asse
 }
 
 func (c collectionClient) GetAssetsByCollection(ctx context.Context, collection holders.Collection) ([]holders.Asset, error) {
-	var createdAssets []holders.Asset
+	candidates, err := c.getCandidateAssets(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []holders.Asset
+	for _, candidate := range candidates {
+		if !collection.Matches(candidate.asset) {
+			continue
+		}
+		assets = append(assets, candidate.asset)
+	}
+	return assets, nil
+}
+
+// ExplainCollection lists every asset created by or listed in the collection with the verdict of each filter
+func (c collectionClient) ExplainCollection(ctx context.Context, collection holders.Collection) ([]holders.AssetExplanation, error) {
+	candidates, err := c.getCandidateAssets(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var explanations []holders.AssetExplanation
+	for _, candidate := range candidates {
+		explanation := holders.AssetExplanation{
+			Asset:    candidate.asset,
+			Creator:  candidate.creator,
+			Included: true,
+		}
+		for _, filter := range collection.AssetFilters() {
+			passed := filter.Match(candidate.asset)
+			explanation.Filters = append(explanation.Filters, holders.FilterVerdict{
+				Filter: filter.Name(),
+				Passed: passed,
+			})
+			explanation.Included = explanation.Included && passed
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+type candidateAsset struct {
+	asset   holders.Asset
+	creator string
+}
+
+// getCandidateAssets returns the assets created by the collection addresses followed by the listed assets
+func (c collectionClient) getCandidateAssets(ctx context.Context, collection holders.Collection) ([]candidateAsset, error) {
+	var candidates []candidateAsset
+	seen := make(map[uint64]bool)
 
 	for _, address := range collection.Addresses {
 		accountInfo, err := c.algodClient.AccountInformation(address).Do(ctx)
@@ -92,29 +145,28 @@ func (c collectionClient) GetAssetsByCollection(ctx context.Context, collection
 			return nil, err
 		}
 		for _, asset := range accountInfo.CreatedAssets {
-			if !matchesUnitNamePrefix(collection.UnitNamePrefixes, asset.Params.UnitName) {
-				continue
-			}
-			if isExcludedAsset(collection.ExcludedAssets, asset.Index) {
-				continue
-			}
-			if !isAssetIndexGreaterThan(collection.AssetIndexGreaterThan, asset.Index) {
-				continue
-			}
-			if !nameContains(collection.IncludeNameContains, asset.Params.Name) {
-				continue
-			}
-			if len(collection.ExcludeNameContains) > 0 && nameContains(collection.ExcludeNameContains, asset.Params.Name) {
-				continue
-			}
-			createdAssets = append(createdAssets, holders.Asset{
-				Name:     asset.Params.Name,
-				UnitName: asset.Params.UnitName,
-				AssetID:  asset.Index,
-			})
+			seen[asset.Index] = true
+			candidates = append(candidates, candidateAsset{asset: toAsset(asset), creator: address})
 		}
 	}
-	return createdAssets, nil
+
+	listedAssetIDs, err := collection.ListedAssetIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range listedAssetIDs {
+		if seen[assetID] {
+			continue
+		}
+		asset, err := c.algodClient.GetAssetByID(assetID).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		seen[assetID] = true
+		candidates = append(candidates, candidateAsset{asset: toAsset(asset), creator: asset.Params.Creator})
+	}
+
+	return candidates, nil
 }
 
 func (c collectionClient) GetAssetHoldingsByCollection(ctx context.Context, collection holders.Collection) ([]holders.AssetHolding, error) {
@@ -136,17 +188,16 @@ func (c collectionClient) GetAssetHoldingsByCollection(ctx context.Context, coll
 			}
 
 			for _, balance := range balancesResponse.Balances {
-				if balance.Amount > 0 &&
-					balance.Deleted == false &&
-					!isExcludedHolderAddress(collection.ExcludedHolderAddresses, balance.Address) {
-
-					holdings = append(holdings, holders.AssetHolding{
-						Address:  balance.Address,
-						Amount:   balance.Amount,
-						AssetID:  asset.AssetID,
-						Name:     asset.Name,
-						UnitName: asset.UnitName,
-					})
+				holding := holders.AssetHolding{
+					Address:  balance.Address,
+					Amount:   balance.Amount,
+					AssetID:  asset.AssetID,
+					Decimals: asset.Decimals,
+					Name:     asset.Name,
+					UnitName: asset.UnitName,
+				}
+				if balance.Deleted == false && collection.IncludesHolding(holding) {
+					holdings = append(holdings, holding)
 				}
 			}
 
@@ -161,54 +212,20 @@ func (c collectionClient) GetAssetHoldingsByCollection(ctx context.Context, coll
 	return holdings, nil
 }
 
-func matchesUnitNamePrefix(unitNamePrefixes []string, unitName string) bool {
-	if len(unitNamePrefixes) == 0 {
-		return true
+func toAsset(asset models.Asset) holders.Asset {
+	return holders.Asset{
+		Name:     asset.Params.Name,
+		UnitName: asset.Params.UnitName,
+		AssetID:  asset.Index,
+		URL:      asset.Params.Url,
+		Total:    asset.Params.Total,
+		Decimals: asset.Params.Decimals,
 	}
-	for _, prefix := range unitNamePrefixes {
-		if strings.HasPrefix(strings.TrimSpace(unitName), strings.TrimSpace(prefix)) {
-			return true
-		}
-	}
-	return false
 }
 
-func isAssetIndexGreaterThan(assetIndexGreaterThan uint64, assetID uint64) bool {
-	return assetID > assetIndexGreaterThan
-}
-
-func isExcludedAsset(excludedAssets []uint64, assetID uint64) bool {
-	if len(excludedAssets) == 0 {
-		return false
-	}
-	for _, excludedAsset := range excludedAssets {
-		if excludedAsset == assetID {
-			return true
-		}
-	}
-	return false
-}
-
-func isExcludedHolderAddress(excludedHolderAddresses []string, address string) bool {
-	if len(excludedHolderAddresses) == 0 {
-		return false
-	}
-	for _, excludedHolderAddress := range excludedHolderAddresses {
-		if excludedHolderAddress == address {
-			return true
-		}
-	}
-	return false
-}
-
-func nameContains(nameContains []string, name string) bool {
-	if len(nameContains) == 0 {
-		return true
-	}
-	for _, nameContainsString := range nameContains {
-		if strings.Contains(strings.ToLower(name), strings.ToLower(nameContainsString)) {
-			return true
-		}
-	}
-	return false
+// isNotFound reports whether err is the SDK's error for a 404 response. common.NotFound can't be matched
+// with errors.As, since it is declared as an error interface that every error satisfies, so the status is read
+// from the "HTTP <code>: <body>" message the SDK builds it from. TestNotFoundResponses pins that format.
+func isNotFound(err error) bool {
+	return strings.HasPrefix(err.Error(), fmt.Sprintf("HTTP %d:", http.StatusNotFound))
 }
@@ -289,3 +289,117 @@ func setupMocks(gotCreatedAssets string, gotBalances string) *apitest.Standalone
 		End()
 	return apitest.NewStandaloneMocks(getCreatedAssetsMock, getBalancesMock)
 }
+
+func TestExplainCollection(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewCollectionClient(nodeCli, idxCli)
+
+	resetTransport := setupMocks(`{
+	  "created-assets": [
+		{
+		  "index": 1,
+		  "params": {
+			"name": "Fren #1",
+			"unit-name": "MFER001"
+		  }
+		},
+		{
+		  "index": 2,
+		  "params": {
+			"name": "Fren Burned",
+			"unit-name": "MFER002"
+		  }
+		}
+	  ]
+	}`, `{"balances": []}`).End()
+	defer resetTransport()
+
+	explanations, err := raffle.ExplainCollection(context.Background(), underTest, raffle.Collection{
+		Addresses:           []string{testdata.TestAccount1Address},
+		UnitNamePrefixes:    []string{"MFER"},
+		ExcludeNameContains: []string{"Burned"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []raffle.AssetExplanation{
+		{
+			Asset:   raffle.Asset{Name: "Fren #1", UnitName: "MFER001", AssetID: 1},
+			Creator: testdata.TestAccount1Address,
+			Filters: []raffle.FilterVerdict{
+				{Filter: "UnitNamePrefixes", Passed: true},
+				{Filter: "ExcludedAssets", Passed: true},
+				{Filter: "AssetIndexGreaterThan", Passed: true},
+				{Filter: "IncludeNameContains", Passed: true},
+				{Filter: "ExcludeNameContains", Passed: true},
+			},
+			Included: true,
+		},
+		{
+			Asset:   raffle.Asset{Name: "Fren Burned", UnitName: "MFER002", AssetID: 2},
+			Creator: testdata.TestAccount1Address,
+			Filters: []raffle.FilterVerdict{
+				{Filter: "UnitNamePrefixes", Passed: true},
+				{Filter: "ExcludedAssets", Passed: true},
+				{Filter: "AssetIndexGreaterThan", Passed: true},
+				{Filter: "IncludeNameContains", Passed: true},
+				{Filter: "ExcludeNameContains", Passed: false},
+			},
+			Included: false,
+		},
+	}, explanations)
+}
+
+func TestGetCollectionByAssetIDs(t *testing.T) {
+	nodeCli, _ := algod.MakeClient("http://localhost:8000", "")
+	idxCli, _ := indexer.MakeClient("http://localhost:9000", "")
+	underTest := algorand.NewCollectionClient(nodeCli, idxCli)
+
+	getAssetMock := apitest.NewMock().
+		Get("/v2/assets/1").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{
+		  "index": 1,
+		  "params": {
+			"creator": "%s",
+			"name": "Fren Token",
+			"unit-name": "FREN",
+			"decimals": 6,
+			"total": 1000000000
+		  }
+		}`, testdata.TestAccount1Address)).
+		End()
+	getBalancesMock := apitest.NewMock().
+		Get("/v2/assets/1/balances").
+		RespondWith().
+		Status(http.StatusOK).
+		JSON(fmt.Sprintf(`{
+		  "balances": [
+			{
+			  "address": "%s",
+			  "amount": 5000000,
+			  "deleted": false
+			}
+		  ]
+		}`, testdata.TestAccount2Address)).
+		End()
+	resetTransport := apitest.NewStandaloneMocks(getAssetMock, getBalancesMock).End()
+	defer resetTransport()
+
+	holdings, err := underTest.GetAssetHoldingsByCollection(context.Background(), raffle.Collection{
+		AssetIDs: []uint64{1},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []raffle.AssetHolding{
+		{
+			Address:  testdata.TestAccount2Address,
+			Amount:   5000000,
+			AssetID:  1,
+			Decimals: 6,
+			Name:     "Fren Token",
+			UnitName: "FREN",
+		},
+	}, holdings)
+}
@@ -0,0 +1,67 @@
+package algorand
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+)
+
+// confirmationRounds is how many rounds WaitForConfirmation waits before giving up
+const confirmationRounds = 10
+
+func NewTransactionSender(algoD *algod.Client, idxClient *indexer.Client) holders.TransactionSender {
+	return &collectionClient{
+		algodClient:   algoD,
+		indexerClient: idxClient,
+	}
+}
+
+func (c collectionClient) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	return c.algodClient.SuggestedParams().Do(ctx)
+}
+
+// CurrentRound is the indexer's round when it is behind algod, since ConfirmedRound falls back to the indexer
+func (c collectionClient) CurrentRound(ctx context.Context) (uint64, error) {
+	status, err := c.algodClient.Status().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	health, err := c.indexerClient.HealthCheck().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return min(status.LastRound, health.Round), nil
+}
+
+func (c collectionClient) SendTransactions(ctx context.Context, signed []byte) error {
+	_, err := c.algodClient.SendRawTransaction(signed).Do(ctx)
+	return err
+}
+
+func (c collectionClient) WaitForConfirmation(ctx context.Context, txID string) (uint64, error) {
+	info, err := future.WaitForConfirmation(c.algodClient, txID, confirmationRounds, ctx)
+	if err != nil {
+		return 0, err
+	}
+	return info.ConfirmedRound, nil
+}
+
+// ConfirmedRound asks algod first, which knows about recent transactions before the indexer does
+func (c collectionClient) ConfirmedRound(ctx context.Context, txID string) (uint64, error) {
+	info, _, err := c.algodClient.PendingTransactionInformation(txID).Do(ctx)
+	if err == nil && info.ConfirmedRound > 0 {
+		return info.ConfirmedRound, nil
+	}
+
+	res, err := c.indexerClient.LookupTransaction(txID).Do(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return res.Transaction.ConfirmedRound, nil
+}
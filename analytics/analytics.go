@@ -0,0 +1,236 @@
+// Package analytics summarises holder snapshots: counts, distribution and concentration.
+// Amounts are in whole units of each asset, so NFTs count one per asset held.
+package analytics
+
+import (
+	"github.com/yellowbackground/holders"
+	"math"
+	"sort"
+)
+
+var DefaultHistogramBounds = []float64{1, 2, 5, 10, 25, 50, 100}
+
+const DefaultTopN = 10
+
+type Options struct {
+	// TopN is the number of top holders to report. Zero or less uses DefaultTopN.
+	TopN int
+	// HistogramBounds are the lower bounds of the holding-size buckets. Defaults to DefaultHistogramBounds.
+	HistogramBounds []float64
+	// ExcludedAddresses, e.g. creator and escrow wallets, are left out of the distribution and reported as ExcludedShare.
+	ExcludedAddresses []string
+}
+
+type HolderAmount struct {
+	Address string
+	Amount  float64
+}
+
+// Bucket counts holders with Min <= amount < Max. The last bucket has no Max and holders
+// below the first bound are not counted.
+type Bucket struct {
+	Min     float64
+	Max     float64
+	Holders int
+}
+
+type Report struct {
+	UniqueHolders  int
+	TotalHeld      float64
+	AverageHolding float64
+	MedianHolding  float64
+	TopHolders     []HolderAmount
+	Histogram      []Bucket
+	// Gini is 0 when every holder holds the same amount and approaches 1 when one holder holds everything.
+	Gini float64
+	// Nakamoto is the smallest number of holders that together hold more than half of TotalHeld.
+	Nakamoto int
+	// ExcludedShare is the fraction of all units held by ExcludedAddresses.
+	ExcludedShare float64
+}
+
+// Analyze reports on a set of holdings, usually those of one collection.
+func Analyze(holdings []holders.AssetHolding, options Options) Report {
+	excluded := make(map[string]bool)
+	for _, address := range options.ExcludedAddresses {
+		excluded[address] = true
+	}
+
+	var excludedHeld float64
+	amountByHolder := make(map[string]float64)
+	for _, holding := range holdings {
+		if excluded[holding.Address] {
+			excludedHeld += holding.DecimalAmount()
+			continue
+		}
+		amountByHolder[holding.Address] += holding.DecimalAmount()
+	}
+
+	amounts := make([]HolderAmount, 0, len(amountByHolder))
+	for address, amount := range amountByHolder {
+		amounts = append(amounts, HolderAmount{Address: address, Amount: amount})
+	}
+	sort.Slice(amounts, func(i, j int) bool {
+		if amounts[i].Amount != amounts[j].Amount {
+			return amounts[i].Amount > amounts[j].Amount
+		}
+		return amounts[i].Address < amounts[j].Address
+	})
+
+	report := Report{UniqueHolders: len(amounts)}
+	for _, amount := range amounts {
+		report.TotalHeld += amount.Amount
+	}
+	if total := report.TotalHeld + excludedHeld; total > 0 {
+		report.ExcludedShare = excludedHeld / total
+	}
+
+	topN := options.TopN
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+	report.TopHolders = amounts[:min(topN, len(amounts))]
+
+	bounds := options.HistogramBounds
+	if len(bounds) == 0 {
+		bounds = DefaultHistogramBounds
+	}
+	report.Histogram = histogram(amounts, bounds)
+
+	if len(amounts) == 0 {
+		return report
+	}
+
+	report.AverageHolding = report.TotalHeld / float64(len(amounts))
+	report.MedianHolding = median(amounts)
+	report.Gini = gini(amounts, report.TotalHeld)
+	report.Nakamoto = nakamoto(amounts, report.TotalHeld)
+
+	return report
+}
+
+// AnalyzeCollections reports on each collection separately.
+func AnalyzeCollections(holdingsByCollection map[string][]holders.AssetHolding, options Options) map[string]Report {
+	reports := make(map[string]Report, len(holdingsByCollection))
+	for collectionName, holdings := range holdingsByCollection {
+		reports[collectionName] = Analyze(holdings, options)
+	}
+	return reports
+}
+
+// Flatten combines the holdings of all collections, e.g. to Analyze them together.
+func Flatten(holdingsByCollection map[string][]holders.AssetHolding) []holders.AssetHolding {
+	var all []holders.AssetHolding
+	for _, collectionName := range collectionNames(holdingsByCollection) {
+		all = append(all, holdingsByCollection[collectionName]...)
+	}
+	return all
+}
+
+// CreatorAddresses returns the creator and excluded holder addresses of the collections,
+// for use as Options.ExcludedAddresses.
+func CreatorAddresses(collections []holders.Collection) []string {
+	var addresses []string
+	for _, collection := range collections {
+		addresses = append(addresses, collection.Addresses...)
+		addresses = append(addresses, collection.ExcludedHolderAddresses...)
+	}
+	return addresses
+}
+
+// OverlapMatrix counts the holders common to each pair of collections.
+// Holders[i][i] is the number of holders of Collections[i].
+type OverlapMatrix struct {
+	Collections []string
+	Holders     [][]int
+}
+
+func Overlap(holdingsByCollection map[string][]holders.AssetHolding) OverlapMatrix {
+	names := collectionNames(holdingsByCollection)
+
+	holderSets := make([]map[string]bool, len(names))
+	for i, name := range names {
+		holderSets[i] = make(map[string]bool)
+		for _, holding := range holdingsByCollection[name] {
+			holderSets[i][holding.Address] = true
+		}
+	}
+
+	matrix := OverlapMatrix{Collections: names, Holders: make([][]int, len(names))}
+	for i := range names {
+		matrix.Holders[i] = make([]int, len(names))
+		for j := range names {
+			for address := range holderSets[i] {
+				if holderSets[j][address] {
+					matrix.Holders[i][j]++
+				}
+			}
+		}
+	}
+	return matrix
+}
+
+func histogram(amounts []HolderAmount, bounds []float64) []Bucket {
+	buckets := make([]Bucket, len(bounds))
+	for i, bound := range bounds {
+		buckets[i].Min = bound
+		if i+1 < len(bounds) {
+			buckets[i].Max = bounds[i+1]
+		}
+	}
+	for _, amount := range amounts {
+		for i := len(buckets) - 1; i >= 0; i-- {
+			if amount.Amount >= buckets[i].Min {
+				buckets[i].Holders++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// median expects amounts sorted in descending order.
+func median(amounts []HolderAmount) float64 {
+	n := len(amounts)
+	if n%2 == 1 {
+		return amounts[n/2].Amount
+	}
+	return (amounts[n/2-1].Amount + amounts[n/2].Amount) / 2
+}
+
+// gini expects amounts sorted in descending order.
+func gini(amounts []HolderAmount, total float64) float64 {
+	n := float64(len(amounts))
+	if total == 0 || n < 2 {
+		return 0
+	}
+	var weightedSum float64
+	for i, amount := range amounts {
+		// rank in ascending order, starting at 1
+		rank := n - float64(i)
+		weightedSum += rank * amount.Amount
+	}
+	g := (2*weightedSum)/(n*total) - (n+1)/n
+	return math.Max(0, g)
+}
+
+// nakamoto expects amounts sorted in descending order.
+func nakamoto(amounts []HolderAmount, total float64) int {
+	var held float64
+	for i, amount := range amounts {
+		held += amount.Amount
+		if held > total/2 {
+			return i + 1
+		}
+	}
+	return len(amounts)
+}
+
+func collectionNames(holdingsByCollection map[string][]holders.AssetHolding) []string {
+	names := make([]string, 0, len(holdingsByCollection))
+	for name := range holdingsByCollection {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package analytics_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/analytics"
+	"testing"
+)
+
+func holdingsOf(collectionAmounts map[string]uint64) []holders.AssetHolding {
+	var holdings []holders.AssetHolding
+	for address, amount := range collectionAmounts {
+		for i := uint64(0); i < amount; i++ {
+			holdings = append(holdings, holders.AssetHolding{Address: address, Amount: 1, AssetID: i})
+		}
+	}
+	return holdings
+}
+
+func TestAnalyze(t *testing.T) {
+	holdings := holdingsOf(map[string]uint64{"A": 6, "B": 2, "C": 1, "D": 1, "CREATOR": 10})
+
+	report := analytics.Analyze(holdings, analytics.Options{
+		TopN:              2,
+		HistogramBounds:   []float64{1, 2, 5},
+		ExcludedAddresses: []string{"CREATOR"},
+	})
+
+	assert.Equal(t, 4, report.UniqueHolders)
+	assert.Equal(t, 10.0, report.TotalHeld)
+	assert.Equal(t, 2.5, report.AverageHolding)
+	assert.Equal(t, 1.5, report.MedianHolding)
+	assert.Equal(t, []analytics.HolderAmount{{Address: "A", Amount: 6}, {Address: "B", Amount: 2}}, report.TopHolders)
+	assert.Equal(t, []analytics.Bucket{{Min: 1, Max: 2, Holders: 2}, {Min: 2, Max: 5, Holders: 1}, {Min: 5, Holders: 1}}, report.Histogram)
+	assert.InDelta(t, 0.4, report.Gini, 0.0001)
+	assert.Equal(t, 1, report.Nakamoto)
+	assert.Equal(t, 0.5, report.ExcludedShare)
+}
+
+func TestAnalyzeEqualHoldings(t *testing.T) {
+	report := analytics.Analyze(holdingsOf(map[string]uint64{"A": 2, "B": 2, "C": 2}), analytics.Options{})
+
+	assert.Equal(t, 0.0, report.Gini)
+	assert.Equal(t, 2, report.Nakamoto)
+}
+
+func TestAnalyzeTopN(t *testing.T) {
+	holdings := holdingsOf(map[string]uint64{"A": 3, "B": 2, "C": 1})
+
+	tests := map[string]struct {
+		TopN int
+		Want int
+	}{
+		"fewer holders than top n": {TopN: 5, Want: 3},
+		"top n":                    {TopN: 2, Want: 2},
+		"default":                  {TopN: 0, Want: 3},
+		"negative uses default":    {TopN: -1, Want: 3},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := analytics.Analyze(holdings, analytics.Options{TopN: test.TopN})
+
+			assert.Len(t, report.TopHolders, test.Want)
+		})
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	matrix := analytics.Overlap(map[string][]holders.AssetHolding{
+		"Mostly Frens": holdingsOf(map[string]uint64{"A": 1, "B": 1, "C": 1}),
+		"Best Frens":   holdingsOf(map[string]uint64{"A": 2, "D": 1}),
+	})
+
+	assert.Equal(t, analytics.OverlapMatrix{
+		Collections: []string{"Best Frens", "Mostly Frens"},
+		Holders:     [][]int{{2, 1}, {1, 3}},
+	}, matrix)
+}
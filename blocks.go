@@ -0,0 +1,18 @@
+package holders
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// BlockClient follows the chain block by block.
+type BlockClient interface {
+	// WaitForBlock returns once the block of round is available.
+	WaitForBlock(ctx context.Context, round uint64) error
+	Block(ctx context.Context, round uint64) (types.Block, error)
+}
+
+// AssetBalancesClient reads every non-zero balance of an asset, unfiltered, and the round they were read at.
+type AssetBalancesClient interface {
+	GetAssetBalances(ctx context.Context, assetID uint64) (map[string]uint64, uint64, error)
+}
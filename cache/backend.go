@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a cached value with the time it was stored.
+type Entry struct {
+	Key      string
+	StoredAt time.Time
+	Value    []byte
+}
+
+// Backend stores cache entries. Implementations must be safe for concurrent use.
+type Backend interface {
+	Get(key string) (Entry, bool, error)
+	Set(entry Entry) error
+	// DeletePrefix removes every entry whose key starts with prefix. An empty prefix removes everything.
+	DeletePrefix(prefix string) error
+}
+
+type memoryBackend struct {
+	mutex   sync.RWMutex
+	entries map[string]Entry
+}
+
+func NewMemoryBackend() Backend {
+	return &memoryBackend{entries: make(map[string]Entry)}
+}
+
+func (b *memoryBackend) Get(key string) (Entry, bool, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	entry, found := b.entries[key]
+	return entry, found, nil
+}
+
+func (b *memoryBackend) Set(entry Entry) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.entries[entry.Key] = entry
+	return nil
+}
+
+func (b *memoryBackend) DeletePrefix(prefix string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for key := range b.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.entries, key)
+		}
+	}
+	return nil
+}
+
+// diskBackend stores one JSON file per entry, named by the hash of its key.
+type diskBackend struct {
+	mutex sync.Mutex
+	dir   string
+}
+
+// NewDiskBackend stores entries as files in dir, creating it if needed, so they survive restarts.
+// Entries that can't be read, e.g. after a crash or a format change, are treated as missing.
+func NewDiskBackend(dir string) (Backend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &diskBackend{dir: dir}, nil
+}
+
+func (b *diskBackend) Get(key string) (Entry, bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	data, err := os.ReadFile(b.path(key))
+	if err != nil {
+		return Entry{}, false, nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+func (b *diskBackend) Set(entry Entry) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so a crash never leaves a partial entry behind
+	tmp, err := os.CreateTemp(b.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), b.path(entry.Key))
+}
+
+func (b *diskBackend) DeletePrefix(prefix string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(b.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil || strings.HasPrefix(entry.Key, prefix) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *diskBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(b.dir, hex.EncodeToString(sum[:])+".json")
+}
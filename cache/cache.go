@@ -0,0 +1,159 @@
+// Package cache wraps a holders.CollectionClient so repeated runs don't fetch data that rarely changes.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/yellowbackground/holders"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	assetsPrefix    = "assets/"
+	holdingsPrefix  = "holdings/"
+	ownershipPrefix = "owned/"
+)
+
+// TTLs is how long each type of data is cached. A zero TTL disables caching for that type.
+type TTLs struct {
+	Assets    time.Duration
+	Holdings  time.Duration
+	Ownership time.Duration
+}
+
+// DefaultTTLs caches the assets of a collection for a day and ownership for an hour.
+// Holdings change with every transfer and are not cached.
+var DefaultTTLs = TTLs{
+	Assets:    24 * time.Hour,
+	Ownership: time.Hour,
+}
+
+// CollectionClient is a caching holders.CollectionClient. It is safe for concurrent use.
+type CollectionClient struct {
+	client  holders.CollectionClient
+	backend Backend
+	ttls    TTLs
+	now     func() time.Time
+}
+
+func NewCollectionClient(client holders.CollectionClient, backend Backend, ttls TTLs) *CollectionClient {
+	return &CollectionClient{
+		client:  client,
+		backend: backend,
+		ttls:    ttls,
+		now:     time.Now,
+	}
+}
+
+// GetAssetsByCollection reads the AssetSource of the collection, if it has one, on every call,
+// so a change to the listed assets misses the cache.
+func (c *CollectionClient) GetAssetsByCollection(ctx context.Context, collection holders.Collection) ([]holders.Asset, error) {
+	key, err := collectionKey(ctx, assetsPrefix, collection, c.ttls.Assets)
+	if err != nil {
+		return nil, err
+	}
+	return cached(c, key, c.ttls.Assets, func() ([]holders.Asset, error) {
+		return c.client.GetAssetsByCollection(ctx, collection)
+	})
+}
+
+func (c *CollectionClient) GetAssetHoldingsByCollection(ctx context.Context, collection holders.Collection) ([]holders.AssetHolding, error) {
+	key, err := collectionKey(ctx, holdingsPrefix, collection, c.ttls.Holdings)
+	if err != nil {
+		return nil, err
+	}
+	return cached(c, key, c.ttls.Holdings, func() ([]holders.AssetHolding, error) {
+		return c.client.GetAssetHoldingsByCollection(ctx, collection)
+	})
+}
+
+func (c *CollectionClient) IsAssetOwned(ctx context.Context, asset holders.Asset) (bool, error) {
+	return cached(c, ownershipKey(asset.AssetID), c.ttls.Ownership, func() (bool, error) {
+		return c.client.IsAssetOwned(ctx, asset)
+	})
+}
+
+// ExplainCollection is not cached since it is used while tuning filters.
+func (c *CollectionClient) ExplainCollection(ctx context.Context, collection holders.Collection) ([]holders.AssetExplanation, error) {
+	return holders.ExplainCollection(ctx, c.client, collection)
+}
+
+// GetAccountAssets passes through to the wrapped client if it supports account lookups.
+func (c *CollectionClient) GetAccountAssets(ctx context.Context, address string) ([]holders.AccountAsset, error) {
+	accountClient, ok := c.client.(holders.AccountAssetsClient)
+	if !ok {
+		return nil, errors.New("collection client does not support account asset lookups")
+	}
+	return accountClient.GetAccountAssets(ctx, address)
+}
+
+// InvalidateCollection drops the cached assets and holdings of a collection.
+func (c *CollectionClient) InvalidateCollection(collectionName string) error {
+	if err := c.backend.DeletePrefix(assetsPrefix + collectionName + "/"); err != nil {
+		return err
+	}
+	return c.backend.DeletePrefix(holdingsPrefix + collectionName + "/")
+}
+
+func (c *CollectionClient) InvalidateAsset(assetID uint64) error {
+	return c.backend.DeletePrefix(ownershipKey(assetID))
+}
+
+func (c *CollectionClient) InvalidateAll() error {
+	return c.backend.DeletePrefix("")
+}
+
+// cached returns a fresh cached value for key, or calls fetch and stores its result.
+func cached[T any](c *CollectionClient, key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	var value T
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	entry, found, err := c.backend.Get(key)
+	if err != nil {
+		return value, err
+	}
+	// entries that can't be decoded are fetched again
+	if found && c.now().Sub(entry.StoredAt) < ttl && json.Unmarshal(entry.Value, &value) == nil {
+		return value, nil
+	}
+
+	value, err = fetch()
+	if err != nil {
+		return value, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value, err
+	}
+	return value, c.backend.Set(Entry{Key: key, StoredAt: c.now(), Value: data})
+}
+
+// collectionKey includes the collection hash so that changing a collection's filters misses the cache.
+// The hash doesn't cover the AssetSource, so the asset IDs it lists are added too.
+func collectionKey(ctx context.Context, prefix string, collection holders.Collection, ttl time.Duration) (string, error) {
+	key := prefix + collection.Name + "/" + holders.HashCollections([]holders.Collection{collection})
+	if collection.AssetSource == nil || ttl <= 0 {
+		return key, nil
+	}
+	assetIDs, err := collection.ListedAssetIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+	listed := make([]string, len(assetIDs))
+	for i, assetID := range assetIDs {
+		listed[i] = strconv.FormatUint(assetID, 10)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(listed, ",")))
+	return key + "/" + hex.EncodeToString(sum[:]), nil
+}
+
+func ownershipKey(assetID uint64) string {
+	return ownershipPrefix + strconv.FormatUint(assetID, 10) + "/"
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/testdata"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectionClient(t *testing.T) {
+	diskBackend, err := NewDiskBackend(t.TempDir())
+	assert.NoError(t, err)
+
+	backends := map[string]Backend{
+		"memory": NewMemoryBackend(),
+		"disk":   diskBackend,
+	}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			fake := &testdata.FakeCollectionClient{
+				Assets: map[string][]holders.Asset{"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}}},
+			}
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			underTest := NewCollectionClient(fake, backend, TTLs{Assets: time.Hour})
+			underTest.now = func() time.Time { return now }
+			collection := holders.Collection{Name: "Mostly Frens"}
+
+			for i := 0; i < 3; i++ {
+				assets, err := underTest.GetAssetsByCollection(context.Background(), collection)
+				assert.NoError(t, err)
+				assert.Equal(t, fake.Assets["Mostly Frens"], assets)
+			}
+			assert.Equal(t, 1, fake.CallCount("GetAssetsByCollection"))
+
+			now = now.Add(time.Hour)
+			_, err := underTest.GetAssetsByCollection(context.Background(), collection)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, fake.CallCount("GetAssetsByCollection"))
+
+			assert.NoError(t, underTest.InvalidateCollection("Mostly Frens"))
+			_, err = underTest.GetAssetsByCollection(context.Background(), collection)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, fake.CallCount("GetAssetsByCollection"))
+
+			_, err = underTest.GetAssetHoldingsByCollection(context.Background(), collection)
+			assert.NoError(t, err)
+			_, err = underTest.GetAssetHoldingsByCollection(context.Background(), collection)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, fake.CallCount("GetAssetHoldingsByCollection"))
+		})
+	}
+}
+
+func TestCollectionClientConcurrentUse(t *testing.T) {
+	fake := &testdata.FakeCollectionClient{}
+	underTest := NewCollectionClient(fake, NewMemoryBackend(), TTLs{Holdings: time.Hour})
+
+	var collections []holders.Collection
+	for _, name := range []string{"A", "B", "C", "D"} {
+		collections = append(collections, holders.Collection{Name: name})
+	}
+	for i := 0; i < 2; i++ {
+		_, err := holders.GetAssetHoldingsByCollection(context.Background(), underTest, collections, 4)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, fake.CallCount("GetAssetHoldingsByCollection"))
+}
+
+func TestCollectionClientAssetSourceChange(t *testing.T) {
+	fake := &testdata.FakeCollectionClient{}
+	underTest := NewCollectionClient(fake, NewMemoryBackend(), TTLs{Assets: time.Hour})
+
+	listed := []uint64{1, 2}
+	collection := holders.Collection{Name: "Listed", AssetSource: holders.AssetSourceFunc(func(ctx context.Context) ([]uint64, error) {
+		return listed, nil
+	})}
+
+	for i := 0; i < 2; i++ {
+		_, err := underTest.GetAssetsByCollection(context.Background(), collection)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, fake.CallCount("GetAssetsByCollection"))
+
+	listed = []uint64{1, 2, 3}
+	_, err := underTest.GetAssetsByCollection(context.Background(), collection)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.CallCount("GetAssetsByCollection"))
+}
+
+func TestDiskBackendUnreadableEntry(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := NewDiskBackend(dir)
+	assert.NoError(t, err)
+
+	fake := &testdata.FakeCollectionClient{
+		Assets: map[string][]holders.Asset{"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}}},
+	}
+	underTest := NewCollectionClient(fake, backend, TTLs{Assets: time.Hour})
+	collection := holders.Collection{Name: "Mostly Frens"}
+
+	_, err = underTest.GetAssetsByCollection(context.Background(), collection)
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.NoError(t, os.WriteFile(files[0], []byte("{truncated"), 0o644))
+
+	assets, err := underTest.GetAssetsByCollection(context.Background(), collection)
+	assert.NoError(t, err)
+	assert.Equal(t, fake.Assets["Mostly Frens"], assets)
+	assert.Equal(t, 2, fake.CallCount("GetAssetsByCollection"))
+}
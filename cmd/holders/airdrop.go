@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders/airdrop"
+	"github.com/yellowbackground/holders/algorand"
+	"github.com/yellowbackground/holders/config"
+	"os"
+	"strconv"
+)
+
+func runAirdrop(env *environment, args []string) error {
+	fs := newFlagSet(env, "airdrop", "-config collections.yaml -asset id -budget amount [-snapshot snapshot.json] [-sender address -txns airdrop.txns]")
+	var source holdingsSource
+	source.register(fs, env)
+	var output outputFlags
+	output.register(fs, formatCSV)
+	assetID := fs.Uint64("asset", 0, "ID of the reward asset")
+	budget := fs.Uint64("budget", 0, "amount to distribute, in base units of the reward asset")
+	minimum := fs.Uint64("min", 0, "drop allocations below this many base units and share them among the rest")
+	redistribute := fs.Bool("redistribute", false, "share the allocations of holders who are not opted in among the rest")
+	includeCreators := fs.Bool("include-creators", false, "allocate to collection creator addresses too")
+	var excluded stringList
+	fs.Var(&excluded, "exclude", "addresses that receive nothing, comma separated or repeated")
+	sender := fs.String("sender", "", "address the reward asset is sent from")
+	txnsPath := fs.String("txns", "", "write unsigned, grouped transfer transactions to this file for offline signing")
+	note := fs.String("note", "", "note added to every transaction")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if source.configPath == "" {
+		return usageError("-config is required")
+	}
+	if *assetID == 0 || *budget == 0 {
+		return usageError("-asset and -budget are required")
+	}
+	if *txnsPath != "" && *sender == "" {
+		return usageError("-sender is required with -txns")
+	}
+
+	weightedCollections, err := config.LoadWeightedCollections(source.configPath)
+	if err != nil {
+		return err
+	}
+	excludedAddresses := []string(excluded)
+	if !*includeCreators {
+		for _, weightedCollection := range weightedCollections {
+			excludedAddresses = append(excludedAddresses, weightedCollection.Collection.Addresses...)
+		}
+	}
+
+	holdings, _, err := source.holdings()
+	if err != nil {
+		return err
+	}
+	algoD, _, err := source.node.clients()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := source.node.context()
+	defer cancel()
+
+	plan, err := airdrop.NewPlan(ctx, algorand.NewAssetOptInClient(algoD), holdings, airdrop.Config{
+		AssetID:           *assetID,
+		Budget:            *budget,
+		Collections:       weightedCollections,
+		MinimumAmount:     *minimum,
+		ExcludedAddresses: excludedAddresses,
+		Redistribute:      *redistribute,
+		Concurrency:       source.node.concurrency,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stderr, "holders airdrop: %d recipients, %d of %d allocated, %d holders rejected\n",
+		len(plan.Allocations), plan.Allocated, plan.Budget, len(plan.Rejections))
+
+	if *txnsPath != "" {
+		params, err := algoD.SuggestedParams().Do(ctx)
+		if err != nil {
+			return err
+		}
+		groups, err := airdrop.BuildTransactions(plan, *sender, params, []byte(*note))
+		if err != nil {
+			return err
+		}
+		file, err := os.Create(*txnsPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := airdrop.WriteUnsignedTransactions(file, groups); err != nil {
+			return err
+		}
+		fmt.Fprintf(env.stderr, "holders airdrop: wrote %d transaction groups to %s\n", len(groups), *txnsPath)
+	}
+
+	return output.write(env, plan, func() table {
+		t := table{header: []string{"address", "weight", "amount", "status"}}
+		for _, allocation := range plan.Allocations {
+			t.rows = append(t.rows, []string{allocation.Address, strconv.FormatUint(allocation.Weight, 10), strconv.FormatUint(allocation.Amount, 10), "send"})
+		}
+		for _, rejection := range plan.Rejections {
+			t.rows = append(t.rows, []string{rejection.Address, strconv.FormatUint(rejection.Weight, 10), strconv.FormatUint(rejection.Amount, 10), rejection.Reason})
+		}
+		return t
+	})
+}
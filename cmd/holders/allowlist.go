@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/merkle"
+	"github.com/yellowbackground/holders/snapshot"
+)
+
+func runAllowlist(env *environment, args []string) error {
+	fs := newFlagSet(env, "allowlist", "-config collections.yaml -snapshot snapshot.json [-o allowlist.json]")
+	configPath := fs.String("config", env.getenv("HOLDERS_CONFIG"), "collections config file with the weight of each collection [HOLDERS_CONFIG]")
+	snapshotPath := fs.String("snapshot", "", "snapshot file to build the allowlist from")
+	outputPath := fs.String("o", "", "write the allowlist to this file instead of stdout")
+	includeCreators := fs.Bool("include-creators", false, "allocate to collection creator addresses too")
+	var excluded stringList
+	fs.Var(&excluded, "exclude", "addresses left off the allowlist, comma separated or repeated")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *configPath == "" || *snapshotPath == "" {
+		return usageError("-config and -snapshot are required")
+	}
+
+	weightedCollections, err := config.LoadWeightedCollections(*configPath)
+	if err != nil {
+		return err
+	}
+	excludedAddresses := []string(excluded)
+	if !*includeCreators {
+		for _, weightedCollection := range weightedCollections {
+			excludedAddresses = append(excludedAddresses, weightedCollection.Collection.Addresses...)
+		}
+	}
+	snap, err := snapshot.Load(*snapshotPath)
+	if err != nil {
+		return err
+	}
+	allowlist, err := merkle.FromSnapshot(snap, weightedCollections, excludedAddresses)
+	if err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		return merkle.Write(env.stdout, allowlist)
+	}
+	return merkle.Save(*outputPath, allowlist)
+}
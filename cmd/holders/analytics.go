@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/yellowbackground/holders/analytics"
+	"sort"
+	"strconv"
+)
+
+// analyticsResult is the JSON output of the analytics command.
+type analyticsResult struct {
+	All         analytics.Report
+	Collections map[string]analytics.Report
+	Overlap     analytics.OverlapMatrix
+}
+
+func runAnalytics(env *environment, args []string) error {
+	fs := newFlagSet(env, "analytics", "(-config collections.yaml | -snapshot snapshot.json) [-top 10]")
+	var source holdingsSource
+	source.register(fs, env)
+	var output outputFlags
+	output.register(fs, formatText)
+	top := fs.Int("top", analytics.DefaultTopN, "number of top holders to report")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if source.configPath == "" && source.snapshotPath == "" {
+		return usageError("-config or -snapshot is required")
+	}
+	if *top < 0 {
+		return usageError("-top must not be negative")
+	}
+
+	options := analytics.Options{TopN: *top}
+	if source.configPath != "" {
+		collections, err := source.collections()
+		if err != nil {
+			return err
+		}
+		options.ExcludedAddresses = analytics.CreatorAddresses(collections)
+	}
+
+	holdings, _, err := source.holdings()
+	if err != nil {
+		return err
+	}
+	result := analyticsResult{
+		All:         analytics.Analyze(analytics.Flatten(holdings), options),
+		Collections: analytics.AnalyzeCollections(holdings, options),
+		Overlap:     analytics.Overlap(holdings),
+	}
+
+	return output.write(env, result, func() table {
+		t := table{header: []string{"collection", "holders", "held", "average", "median", "gini", "nakamoto", "excluded_share"}}
+		addRow := func(name string, report analytics.Report) {
+			t.rows = append(t.rows, []string{
+				name,
+				strconv.Itoa(report.UniqueHolders),
+				formatFloat(report.TotalHeld),
+				strconv.FormatFloat(report.AverageHolding, 'f', 2, 64),
+				formatFloat(report.MedianHolding),
+				strconv.FormatFloat(report.Gini, 'f', 3, 64),
+				strconv.Itoa(report.Nakamoto),
+				strconv.FormatFloat(report.ExcludedShare, 'f', 3, 64),
+			})
+		}
+		names := make([]string, 0, len(result.Collections))
+		for name := range result.Collections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			addRow(name, result.Collections[name])
+		}
+		addRow("(all)", result.All)
+		return t
+	})
+}
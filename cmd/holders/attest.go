@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders/payout"
+	"github.com/yellowbackground/holders/snapshot"
+)
+
+func runSign(env *environment, args []string) error {
+	fs := newFlagSet(env, "sign", "-key signer.mnemonic [-o signed.json] snapshot.json")
+	keyPath := fs.String("key", env.getenv("HOLDERS_SIGN_KEY"), "file with the 25 word mnemonic of the signing account [HOLDERS_SIGN_KEY]")
+	outputPath := fs.String("o", "", "write the signed snapshot to this file instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *keyPath == "" {
+		return usageError("-key is required")
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected one snapshot file, got %d", fs.NArg())
+	}
+
+	snap, err := snapshot.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	signed, err := signSnapshot(snap, *keyPath)
+	if err != nil {
+		return err
+	}
+	if *outputPath == "" {
+		return snapshot.Write(env.stdout, signed)
+	}
+	return snapshot.Save(*outputPath, signed)
+}
+
+func runVerify(env *environment, args []string) error {
+	fs := newFlagSet(env, "verify", "-signer address snapshot.json")
+	signer := fs.String("signer", env.getenv("HOLDERS_SIGNER"), "address the snapshot must be signed by [HOLDERS_SIGNER]")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *signer == "" {
+		return usageError("-signer is required")
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected one snapshot file, got %d", fs.NArg())
+	}
+
+	snap, err := snapshot.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Verify(snap, *signer); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "%s: signed by %s at round %d\n", fs.Arg(0), *signer, snap.Round)
+	return nil
+}
+
+func signSnapshot(snap snapshot.Snapshot, keyPath string) (snapshot.Snapshot, error) {
+	privateKey, err := payout.LoadMnemonicFile(keyPath)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	return snapshot.Sign(snap, privateKey)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders/snapshot"
+	"strconv"
+)
+
+func runDiff(env *environment, args []string) error {
+	fs := newFlagSet(env, "diff", "[-exit-code] old.json new.json")
+	var output outputFlags
+	output.register(fs, formatText)
+	exitCode := fs.Bool("exit-code", false, "exit with code 3 when the snapshots differ")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageError("expected two snapshot files, got %d", fs.NArg())
+	}
+
+	from, err := snapshot.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := snapshot.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if from.CollectionsHash != to.CollectionsHash {
+		fmt.Fprintln(env.stderr, "holders diff: warning: the snapshots were taken with different collection configs")
+	}
+
+	diff := snapshot.Compare(from, to)
+	err = output.write(env, diff, func() table {
+		t := table{header: []string{"collection", "change", "address", "before", "after", "asset_id", "to"}}
+		for _, c := range diff.Collections {
+			for _, address := range c.NewHolders {
+				t.rows = append(t.rows, []string{c.Collection, "new_holder", address, "", "", "", ""})
+			}
+			for _, address := range c.ExitedHolders {
+				t.rows = append(t.rows, []string{c.Collection, "exited_holder", address, "", "", "", ""})
+			}
+			for _, change := range c.BalanceChanges {
+				t.rows = append(t.rows, []string{c.Collection, "balance", change.Address, formatFloat(change.Before), formatFloat(change.After), "", ""})
+			}
+			for _, transfer := range c.Transfers {
+				t.rows = append(t.rows, []string{c.Collection, "transfer", transfer.From, "", strconv.FormatUint(transfer.Amount, 10), strconv.FormatUint(transfer.AssetID, 10), transfer.To})
+			}
+		}
+		return t
+	})
+	if err != nil {
+		return err
+	}
+
+	if *exitCode {
+		for _, c := range diff.Collections {
+			if !c.IsEmpty() {
+				return &exitCodeError{code: exitChanges}
+			}
+		}
+	}
+	return nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/yellowbackground/holders"
+	"strconv"
+	"strings"
+)
+
+func runExplain(env *environment, args []string) error {
+	fs := newFlagSet(env, "explain", "-config collections.yaml -collection name")
+	var node nodeFlags
+	node.register(fs, env)
+	var output outputFlags
+	output.register(fs, formatJSON)
+	configPath := fs.String("config", env.getenv("HOLDERS_CONFIG"), "collections config file [HOLDERS_CONFIG]")
+	collectionName := fs.String("collection", "", "name of the collection to explain")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if *configPath == "" || *collectionName == "" {
+		return usageError("-config and -collection are required")
+	}
+
+	source := holdingsSource{configPath: *configPath}
+	collections, err := source.collections()
+	if err != nil {
+		return err
+	}
+	var collection *holders.Collection
+	for i := range collections {
+		if collections[i].Name == *collectionName {
+			collection = &collections[i]
+		}
+	}
+	if collection == nil {
+		return usageError("collection %q is not in %s", *collectionName, *configPath)
+	}
+
+	client, err := node.collectionClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := node.context()
+	defer cancel()
+
+	explanations, err := holders.ExplainCollection(ctx, client, *collection)
+	if err != nil {
+		return err
+	}
+
+	return output.write(env, explanations, func() table {
+		t := table{header: []string{"asset_id", "unit_name", "name", "included", "failed_filters"}}
+		for _, explanation := range explanations {
+			var failed []string
+			for _, verdict := range explanation.Filters {
+				if !verdict.Passed {
+					failed = append(failed, verdict.Filter)
+				}
+			}
+			t.rows = append(t.rows, []string{
+				strconv.FormatUint(explanation.Asset.AssetID, 10),
+				explanation.Asset.UnitName,
+				explanation.Asset.Name,
+				strconv.FormatBool(explanation.Included),
+				strings.Join(failed, " "),
+			})
+		}
+		return t
+	})
+}
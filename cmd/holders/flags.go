@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/common"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/algorand"
+	"github.com/yellowbackground/holders/cache"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/snapshot"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAlgodURL   = "https://mainnet-api.algonode.cloud"
+	defaultIndexerURL = "https://mainnet-idx.algonode.cloud"
+)
+
+// nodeFlags are the algod and indexer endpoints shared by commands that talk to the network.
+type nodeFlags struct {
+	algodURL     string
+	algodToken   string
+	indexerURL   string
+	indexerToken string
+	referer      string
+	concurrency  int
+	cacheDir     string
+	timeout      time.Duration
+}
+
+func (f *nodeFlags) register(fs *flag.FlagSet, env *environment) {
+	fs.StringVar(&f.algodURL, "algod-url", envOr(env, "HOLDERS_ALGOD_URL", defaultAlgodURL), "algod endpoint [HOLDERS_ALGOD_URL]")
+	fs.StringVar(&f.algodToken, "algod-token", env.getenv("HOLDERS_ALGOD_TOKEN"), "algod API token [HOLDERS_ALGOD_TOKEN]")
+	fs.StringVar(&f.indexerURL, "indexer-url", envOr(env, "HOLDERS_INDEXER_URL", defaultIndexerURL), "indexer endpoint [HOLDERS_INDEXER_URL]")
+	fs.StringVar(&f.indexerToken, "indexer-token", env.getenv("HOLDERS_INDEXER_TOKEN"), "indexer API token [HOLDERS_INDEXER_TOKEN]")
+	fs.StringVar(&f.referer, "referer", env.getenv("HOLDERS_REFERER"), "Referer header sent to the nodes, required by some public nodes [HOLDERS_REFERER]")
+	fs.IntVar(&f.concurrency, "concurrency", 1, "number of collections fetched at once")
+	fs.StringVar(&f.cacheDir, "cache-dir", "", "cache collection assets in this directory between runs")
+	fs.DurationVar(&f.timeout, "timeout", 30*time.Minute, "give up after this long")
+}
+
+func (f *nodeFlags) clients() (*algod.Client, *indexer.Client, error) {
+	var headers []*common.Header
+	if f.referer != "" {
+		headers = append(headers, &common.Header{Key: "Referer", Value: f.referer})
+	}
+	algoD, err := algod.MakeClientWithHeaders(f.algodURL, f.algodToken, headers)
+	if err != nil {
+		return nil, nil, fmt.Errorf("algod: %w", err)
+	}
+	idx, err := indexer.MakeClientWithHeaders(f.indexerURL, f.indexerToken, headers)
+	if err != nil {
+		return nil, nil, fmt.Errorf("indexer: %w", err)
+	}
+	return algoD, idx, nil
+}
+
+func (f *nodeFlags) collectionClient() (holders.CollectionClient, error) {
+	algoD, idx, err := f.clients()
+	if err != nil {
+		return nil, err
+	}
+	client := algorand.NewCollectionClient(algoD, idx)
+	if f.cacheDir == "" {
+		return client, nil
+	}
+	backend, err := cache.NewDiskBackend(f.cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewCollectionClient(client, backend, cache.DefaultTTLs), nil
+}
+
+func (f *nodeFlags) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), f.timeout)
+}
+
+// holdingsSource reads holdings from a snapshot file when given, otherwise from the network.
+type holdingsSource struct {
+	node         nodeFlags
+	configPath   string
+	snapshotPath string
+}
+
+func (s *holdingsSource) register(fs *flag.FlagSet, env *environment) {
+	s.node.register(fs, env)
+	fs.StringVar(&s.configPath, "config", env.getenv("HOLDERS_CONFIG"), "collections config file [HOLDERS_CONFIG]")
+	fs.StringVar(&s.snapshotPath, "snapshot", "", "read holdings from this snapshot file instead of the network")
+}
+
+func (s *holdingsSource) collections() ([]holders.Collection, error) {
+	if s.configPath == "" {
+		return nil, usageError("-config is required")
+	}
+	return config.LoadCollections(s.configPath)
+}
+
+// holdings returns the holdings and the round of the snapshot, or round 0 when they are read from the network.
+func (s *holdingsSource) holdings() (map[string][]holders.AssetHolding, uint64, error) {
+	if s.snapshotPath != "" {
+		snap, err := snapshot.Load(s.snapshotPath)
+		if err != nil {
+			return nil, 0, err
+		}
+		return snap.Holdings, snap.Round, nil
+	}
+
+	collections, err := s.collections()
+	if err != nil {
+		return nil, 0, err
+	}
+	client, err := s.node.collectionClient()
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := s.node.context()
+	defer cancel()
+	holdings, err := holders.GetAssetHoldingsByCollection(ctx, client, collections, s.node.concurrency)
+	return holdings, 0, err
+}
+
+// stringList is a repeatable, comma separated flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func envOr(env *environment, key string, fallback string) string {
+	if value := env.getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func newFlagSet(env *environment, name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "Usage: holders %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags turns flag errors into usage errors. The flag package has already printed them.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return &exitCodeError{code: exitUsage}
+	}
+	return nil
+}
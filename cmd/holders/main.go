@@ -0,0 +1,127 @@
+// Command holders takes holder snapshots, runs raffles, reports on collections and serves them over HTTP.
+//
+// Usage:
+//
+//	holders <command> [flags]
+//
+// Node endpoints and tokens are read from flags or the HOLDERS_ALGOD_URL, HOLDERS_ALGOD_TOKEN,
+// HOLDERS_INDEXER_URL, HOLDERS_INDEXER_TOKEN and HOLDERS_REFERER environment variables.
+// Collections are read from a YAML or JSON config file, see the config package.
+//
+// Exit codes are 0 on success, 1 on errors, 2 on usage errors and 3 when diff --exit-code finds changes.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK      = 0
+	exitError   = 1
+	exitUsage   = 2
+	exitChanges = 3
+)
+
+type command struct {
+	description string
+	run         func(env *environment, args []string) error
+}
+
+var commands = map[string]command{
+	"snapshot":  {"save the holders of every collection to a snapshot file", runSnapshot},
+	"raffle":    {"pick weighted raffle winners from holders or a snapshot", runRaffle},
+	"diff":      {"compare two snapshots", runDiff},
+	"explain":   {"show why each asset of a collection is included or excluded", runExplain},
+	"analytics": {"report holder counts, distribution and concentration", runAnalytics},
+	"airdrop":   {"plan a pro-rata airdrop and export unsigned transactions", runAirdrop},
+	"serve":     {"serve holders, raffles and snapshots over HTTP", runServe},
+	"allowlist": {"build a Merkle allowlist with a proof for every holder of a snapshot", runAllowlist},
+	"sign":      {"sign a snapshot with an Algorand account", runSign},
+	"verify":    {"check a snapshot was signed by an address and is unchanged", runVerify},
+	"roles":     {"work out the community roles each user should gain or lose", runRoles},
+}
+
+// environment is what commands read and write, so they can be run from tests.
+type environment struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// exitCodeError carries a specific exit code out of a command.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func usageError(format string, args ...interface{}) error {
+	return &exitCodeError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &environment{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}))
+}
+
+func run(args []string, env *environment) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(env.stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd, found := commands[args[0]]
+	if !found {
+		fmt.Fprintf(env.stderr, "holders: unknown command %q\n", args[0])
+		printUsage(env.stderr)
+		return exitUsage
+	}
+
+	err := cmd.run(env, args[1:])
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		if codeErr.err != nil {
+			fmt.Fprintf(env.stderr, "holders %s: %v\n", args[0], codeErr.err)
+		}
+		return codeErr.code
+	}
+	fmt.Fprintf(env.stderr, "holders %s: %v\n", args[0], err)
+	return exitError
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: holders <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'holders <command> -h' for the flags of a command.")
+}
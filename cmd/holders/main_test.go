@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/mnemonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/history"
+	"github.com/yellowbackground/holders/snapshot"
+	"github.com/yellowbackground/holders/testdata"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+	assert.NoError(t, snapshot.Save(before, snapshot.Snapshot{Version: snapshot.Version, Round: 1, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "A", Amount: 1, AssetID: 1}},
+	}}))
+	assert.NoError(t, snapshot.Save(after, snapshot.Snapshot{Version: snapshot.Version, Round: 2, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "B", Amount: 1, AssetID: 1}},
+	}}))
+
+	users := filepath.Join(dir, "users.json")
+	assert.NoError(t, os.WriteFile(users, []byte(`[{"ID": "bob", "Addresses": ["B"], "Roles": ["Whale"]}]`), 0600))
+	roleConfig := filepath.Join(dir, "roles.yaml")
+	assert.NoError(t, os.WriteFile(roleConfig, []byte(`roles:
+  - name: Fren
+    rule: any "Mostly Frens"
+  - name: Whale
+    rule: total >= 25
+collections:
+  - name: Mostly Frens
+    addresses: [`+testdata.TestAccount1Address+`]
+`), 0600))
+
+	tests := map[string]struct {
+		Args       []string
+		WantCode   int
+		WantStdout string
+	}{
+		"no command": {
+			WantCode: exitUsage,
+		},
+		"unknown command": {
+			Args:     []string{"nope"},
+			WantCode: exitUsage,
+		},
+		"unknown flag": {
+			Args:     []string{"diff", "-nope"},
+			WantCode: exitUsage,
+		},
+		"missing config": {
+			Args:     []string{"raffle"},
+			WantCode: exitUsage,
+		},
+		"cooldown without history": {
+			Args:     []string{"raffle", "-config", "../../examples/collections.yaml", "-snapshot", after, "-cooldown-draws", "1"},
+			WantCode: exitUsage,
+		},
+		"diff without changes": {
+			Args:       []string{"diff", "-exit-code", "-format", "csv", before, before},
+			WantCode:   exitOK,
+			WantStdout: "collection,change,address,before,after,asset_id,to\n",
+		},
+		"diff with changes": {
+			Args:     []string{"diff", "-exit-code", "-format", "csv", before, after},
+			WantCode: exitChanges,
+			WantStdout: "collection,change,address,before,after,asset_id,to\n" +
+				"Mostly Frens,new_holder,B,,,,\n" +
+				"Mostly Frens,exited_holder,A,,,,\n" +
+				"Mostly Frens,balance,A,1,0,,\n" +
+				"Mostly Frens,balance,B,0,1,,\n" +
+				"Mostly Frens,transfer,A,,1,1,B\n",
+		},
+		"roles": {
+			Args:       []string{"roles", "-config", roleConfig, "-snapshot", after, "-users", users, "-format", "csv"},
+			WantCode:   exitOK,
+			WantStdout: "user,add,remove\nbob,Fren,Whale\n",
+		},
+		"analytics with negative top": {
+			Args:     []string{"analytics", "-snapshot", after, "-top", "-1"},
+			WantCode: exitUsage,
+		},
+		"airdrop without budget": {
+			Args:     []string{"airdrop", "-config", "../../examples/collections.yaml", "-snapshot", after, "-asset", "1"},
+			WantCode: exitUsage,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			env := &environment{stdout: &stdout, stderr: &stderr, getenv: func(string) string { return "" }}
+
+			code := run(test.Args, env)
+
+			assert.Equal(t, test.WantCode, code, stderr.String())
+			assert.Equal(t, test.WantStdout, stdout.String())
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	account := crypto.GenerateAccount()
+	words, _ := mnemonic.FromPrivateKey(account.PrivateKey)
+	keyPath := filepath.Join(dir, "signer.mnemonic")
+	assert.NoError(t, os.WriteFile(keyPath, []byte(words), 0600))
+	unsignedPath := filepath.Join(dir, "snapshot.json")
+	signedPath := filepath.Join(dir, "signed.json")
+	assert.NoError(t, snapshot.Save(unsignedPath, snapshot.Snapshot{Version: snapshot.Version, Round: 1, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "A", Amount: 1, AssetID: 1}},
+	}}))
+	env := &environment{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, getenv: func(string) string { return "" }}
+
+	assert.Equal(t, exitOK, run([]string{"sign", "-key", keyPath, "-o", signedPath, unsignedPath}, env))
+	assert.Equal(t, exitOK, run([]string{"verify", "-signer", account.Address.String(), signedPath}, env))
+	assert.Equal(t, exitError, run([]string{"verify", "-signer", account.Address.String(), unsignedPath}, env))
+	assert.Equal(t, exitError, run([]string{"verify", "-signer", crypto.GenerateAccount().Address.String(), signedPath}, env))
+	assert.Equal(t, exitUsage, run([]string{"verify", signedPath}, env))
+}
+
+func TestRaffleHistory(t *testing.T) {
+	dir := t.TempDir()
+	snapshotPath := filepath.Join(dir, "snapshot.json")
+	historyPath := filepath.Join(dir, "raffles.jsonl")
+	assert.NoError(t, snapshot.Save(snapshotPath, snapshot.Snapshot{Version: snapshot.Version, Round: 7, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens":   {{Address: "A", Amount: 1, AssetID: 1}, {Address: "B", Amount: 1, AssetID: 2}},
+		"Not Configured": {{Address: "C", Amount: 1, AssetID: 3}},
+	}}))
+	env := &environment{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, getenv: func(string) string { return "" }}
+	draw := func(id string) int {
+		return run([]string{"raffle", "-config", "../../examples/collections.yaml", "-snapshot", snapshotPath,
+			"-history", historyPath, "-cooldown-draws", "1", "-draw-id", id, "-seed", "same seed"}, env)
+	}
+
+	assert.Equal(t, exitOK, draw("first"))
+	assert.Equal(t, exitOK, draw("second"))
+	assert.Equal(t, exitError, draw("second"))
+
+	ledger, err := history.OpenLedger(historyPath)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	draws := ledger.Draws()
+	assert.Len(t, draws, 2)
+	assert.Equal(t, uint64(7), draws[0].Round)
+	assert.Equal(t, &history.Cooldown{Draws: 1}, draws[0].Cooldown)
+	// only the configured collections are drawn from
+	configuredHash, err := snapshot.HashHoldings(map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "A", Amount: 1, AssetID: 1}, {Address: "B", Amount: 1, AssetID: 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, configuredHash, draws[0].HoldingsHash)
+	// the same seed would pick the same winner, but the first winner sits out the second draw
+	assert.NotEqual(t, draws[0].Winners[0].Address, draws[1].Winners[0].Address)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatText = "text"
+)
+
+// table is the tabular form of a command's result, used for csv and text output.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+type outputFlags struct {
+	format string
+	path   string
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet, defaultFormat string) {
+	fs.StringVar(&f.format, "format", defaultFormat, "output format: json, csv or text")
+	fs.StringVar(&f.path, "o", "", "write output to this file instead of stdout")
+}
+
+func (f *outputFlags) validate() error {
+	switch f.format {
+	case formatJSON, formatCSV, formatText:
+		return nil
+	default:
+		return usageError("unknown -format %q, expected json, csv or text", f.format)
+	}
+}
+
+// write outputs value as JSON, or its table as CSV or aligned text.
+func (f *outputFlags) write(env *environment, value interface{}, toTable func() table) error {
+	w := env.stdout
+	if f.path != "" {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch f.format {
+	case formatCSV:
+		t := toTable()
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case formatText:
+		return writeText(w, toTable())
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
+
+func writeText(w io.Writer, t table) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := io.WriteString(writer, strings.Join(t.header, "\t")+"\n"); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if _, err := io.WriteString(writer, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/history"
+	"strconv"
+	"time"
+)
+
+func runRaffle(env *environment, args []string) error {
+	fs := newFlagSet(env, "raffle", "-config collections.yaml [-winners 3] [-seed text] [-snapshot snapshot.json]")
+	var source holdingsSource
+	source.register(fs, env)
+	var output outputFlags
+	output.register(fs, formatText)
+	winners := fs.Int("winners", 1, "number of winners")
+	seed := fs.String("seed", "", "seed that makes the draw reproducible")
+	includeCreators := fs.Bool("include-creators", false, "allow collection creator addresses to win")
+	var excluded stringList
+	fs.Var(&excluded, "exclude", "addresses that may not win, comma separated or repeated")
+	historyPath := fs.String("history", "", "record the draw in this raffle history file, and apply the cooldown to its recent winners")
+	drawID := fs.String("draw-id", "", "unique ID of the draw in the history, defaults to the current time")
+	var cooldown history.Cooldown
+	fs.IntVar(&cooldown.Draws, "cooldown-draws", 0, "winners of this many recent draws sit out, see -cooldown-divisor")
+	fs.DurationVar(&cooldown.Duration, "cooldown", 0, "winners of draws within this duration sit out, see -cooldown-divisor")
+	fs.Uint64Var(&cooldown.Divisor, "cooldown-divisor", 0, "make recent winners this many times less likely to win instead of excluding them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if source.configPath == "" {
+		return usageError("-config is required")
+	}
+	if *winners < 1 {
+		return usageError("-winners must be at least 1")
+	}
+	if cooldown != (history.Cooldown{}) && *historyPath == "" {
+		return usageError("-cooldown* require -history")
+	}
+
+	weightedCollections, err := config.LoadWeightedCollections(source.configPath)
+	if err != nil {
+		return err
+	}
+	excludedWallets := []string(excluded)
+	if !*includeCreators {
+		for _, weightedCollection := range weightedCollections {
+			excludedWallets = append(excludedWallets, weightedCollection.Collection.Addresses...)
+		}
+	}
+
+	allHoldings, round, err := source.holdings()
+	if err != nil {
+		return err
+	}
+	// a snapshot may hold collections that aren't in the config
+	holdings := make(map[string][]holders.AssetHolding, len(weightedCollections))
+	for _, weightedCollection := range weightedCollections {
+		if collectionHoldings, found := allHoldings[weightedCollection.Collection.Name]; found {
+			holdings[weightedCollection.Collection.Name] = collectionHoldings
+		}
+	}
+	raffleConfig := holders.RaffleConfig{
+		RandSeed:              *seed,
+		NumberOfWinners:       *winners,
+		ExcludedWinnerWallets: excludedWallets,
+	}
+
+	var ledger *history.Ledger
+	if *historyPath != "" {
+		ledger, err = history.OpenLedger(*historyPath)
+		if err != nil {
+			return err
+		}
+		defer ledger.Close()
+		if *drawID == "" {
+			*drawID = time.Now().UTC().Format(time.RFC3339Nano)
+		}
+		if ledger.Recorded(*drawID) {
+			return fmt.Errorf("draw %q is already recorded in %s", *drawID, *historyPath)
+		}
+		raffleConfig, weightedCollections, err = ledger.ApplyCooldown(cooldown, time.Now(), raffleConfig, weightedCollections, holdings)
+		if err != nil {
+			return err
+		}
+	}
+
+	winningAssets, err := holders.RunWeightedRaffle(holdings, weightedCollections, raffleConfig)
+	if err != nil {
+		return err
+	}
+
+	if ledger != nil {
+		draw, err := history.NewDraw(*drawID, holdings, round, weightedCollections, raffleConfig, winningAssets)
+		if err != nil {
+			return err
+		}
+		if cooldown != (history.Cooldown{}) {
+			draw.Cooldown = &cooldown
+		}
+		if err := ledger.Record(draw); err != nil {
+			return err
+		}
+	}
+
+	return output.write(env, winningAssets, func() table {
+		t := table{header: []string{"place", "address", "asset_id", "unit_name", "name"}}
+		for i, winner := range winningAssets {
+			t.rows = append(t.rows, []string{strconv.Itoa(i + 1), winner.Address, strconv.FormatUint(winner.AssetID, 10), winner.UnitName, winner.Name})
+		}
+		return t
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/roles"
+	"github.com/yellowbackground/holders/snapshot"
+	"os"
+	"strings"
+)
+
+func runRoles(env *environment, args []string) error {
+	fs := newFlagSet(env, "roles", "-config collections.yaml -snapshot snapshot.json -users users.json [older.json ...]")
+	var output outputFlags
+	output.register(fs, formatJSON)
+	configPath := fs.String("config", env.getenv("HOLDERS_CONFIG"), "collections config file with the roles [HOLDERS_CONFIG]")
+	snapshotPath := fs.String("snapshot", "", "snapshot of the current holdings")
+	usersPath := fs.String("users", "", "JSON list of users with their ID, linked Addresses and current Roles")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	if *configPath == "" || *snapshotPath == "" || *usersPath == "" {
+		return usageError("-config, -snapshot and -users are required")
+	}
+
+	managedRoles, err := config.LoadRoles(*configPath)
+	if err != nil {
+		return err
+	}
+	current, err := snapshot.Load(*snapshotPath)
+	if err != nil {
+		return err
+	}
+	var history []snapshot.Snapshot
+	for _, path := range fs.Args() {
+		snap, err := snapshot.Load(path)
+		if err != nil {
+			return err
+		}
+		history = append(history, snap)
+	}
+	data, err := os.ReadFile(*usersPath)
+	if err != nil {
+		return err
+	}
+	var users []roles.User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+
+	engine, err := roles.NewEngine(managedRoles)
+	if err != nil {
+		return err
+	}
+	changes, err := engine.Evaluate(users, current, history)
+	if err != nil {
+		return err
+	}
+	return output.write(env, changes, func() table {
+		t := table{header: []string{"user", "add", "remove"}}
+		for _, change := range changes {
+			t.rows = append(t.rows, []string{change.UserID, strings.Join(change.Add, ";"), strings.Join(change.Remove, ";")})
+		}
+		return t
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/server"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runServe(env *environment, args []string) error {
+	fs := newFlagSet(env, "serve", "-config collections.yaml [-addr :8080] [-snapshot-dir dir]")
+	var node nodeFlags
+	node.register(fs, env)
+	configPath := fs.String("config", env.getenv("HOLDERS_CONFIG"), "collections config file [HOLDERS_CONFIG]")
+	addr := fs.String("addr", envOr(env, "HOLDERS_ADDR", ":8080"), "address to listen on [HOLDERS_ADDR]")
+	snapshotDir := fs.String("snapshot-dir", "", "serve the snapshot files in this directory")
+	holdingsTTL := fs.Duration("holdings-ttl", 5*time.Minute, "how long holdings are cached, 0 to disable")
+	requestTimeout := fs.Duration("request-timeout", time.Minute, "how long a request may spend fetching holders")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "how long to wait for requests in flight when stopping")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return usageError("-config is required")
+	}
+
+	weightedCollections, err := config.LoadWeightedCollections(*configPath)
+	if err != nil {
+		return err
+	}
+	client, err := node.collectionClient()
+	if err != nil {
+		return err
+	}
+	handler := server.New(client, server.Options{
+		Collections:    weightedCollections,
+		SnapshotDir:    *snapshotDir,
+		HoldingsTTL:    *holdingsTTL,
+		Concurrency:    node.concurrency,
+		RequestTimeout: *requestTimeout,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(env.stderr, "holders serve: listening on %s\n", *addr)
+	return server.ListenAndServe(ctx, *addr, handler, *shutdownTimeout)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/snapshot"
+	"strings"
+)
+
+func runSnapshot(env *environment, args []string) error {
+	fs := newFlagSet(env, "snapshot", "-config collections.yaml [-o snapshot.json]")
+	var node nodeFlags
+	node.register(fs, env)
+	configPath := fs.String("config", env.getenv("HOLDERS_CONFIG"), "collections config file [HOLDERS_CONFIG]")
+	outputPath := fs.String("o", "", "write the snapshot to this file instead of stdout")
+	keyPath := fs.String("sign-key", env.getenv("HOLDERS_SIGN_KEY"), "sign the snapshot with the account whose mnemonic is in this file [HOLDERS_SIGN_KEY]")
+	var metadata stringList
+	fs.Var(&metadata, "meta", "key=value metadata to store in the snapshot, repeatable")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return usageError("-config is required")
+	}
+
+	collections, err := config.LoadCollections(*configPath)
+	if err != nil {
+		return err
+	}
+	client, err := node.collectionClient()
+	if err != nil {
+		return err
+	}
+	algoD, _, err := node.clients()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := node.context()
+	defer cancel()
+
+	status, err := algoD.Status().Do(ctx)
+	if err != nil {
+		return err
+	}
+	holdings, err := holders.GetAssetHoldingsByCollection(ctx, client, collections, node.concurrency)
+	if err != nil {
+		return err
+	}
+
+	snap := snapshot.New(holdings, collections, status.LastRound)
+	for _, entry := range metadata {
+		key, value, _ := strings.Cut(entry, "=")
+		snap.Metadata[key] = value
+	}
+	if *keyPath != "" {
+		if snap, err = signSnapshot(snap, *keyPath); err != nil {
+			return err
+		}
+	}
+
+	if *outputPath == "" {
+		return snapshot.Write(env.stdout, snap)
+	}
+	return snapshot.Save(*outputPath, snap)
+}
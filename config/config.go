@@ -0,0 +1,422 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/roles"
+	"github.com/yellowbackground/holders/rules"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// CollectionConfig is a collection as written in a config file.
+type CollectionConfig struct {
+	Name                    string            `yaml:"name" json:"name"`
+	Addresses               []string          `yaml:"addresses" json:"addresses"`
+	AssetIDs                []uint64          `yaml:"asset_ids" json:"asset_ids"`
+	AssetIDsFile            string            `yaml:"asset_ids_file" json:"asset_ids_file"`
+	AssetIDsURL             string            `yaml:"asset_ids_url" json:"asset_ids_url"`
+	UnitNamePrefixes        []string          `yaml:"unit_name_prefixes" json:"unit_name_prefixes"`
+	ExcludedAssets          []uint64          `yaml:"excluded_assets" json:"excluded_assets"`
+	AssetIndexGreaterThan   uint64            `yaml:"asset_index_greater_than" json:"asset_index_greater_than"`
+	ExcludedHolderAddresses []string          `yaml:"excluded_holder_addresses" json:"excluded_holder_addresses"`
+	IncludeNameContains     []string          `yaml:"include_name_contains" json:"include_name_contains"`
+	ExcludeNameContains     []string          `yaml:"exclude_name_contains" json:"exclude_name_contains"`
+	MinimumBalance          float64           `yaml:"minimum_balance" json:"minimum_balance"`
+	Weight                  *uint64           `yaml:"weight" json:"weight"`
+	AmountWeighting         string            `yaml:"amount_weighting" json:"amount_weighting"`
+	BracketSize             float64           `yaml:"bracket_size" json:"bracket_size"`
+	AssetWeights            map[string]uint64 `yaml:"asset_weights" json:"asset_weights"`
+	AssetWeightsCSV         string            `yaml:"asset_weights_csv" json:"asset_weights_csv"`
+
+	node *yaml.Node
+}
+
+// RuleConfig is a named eligibility rule, see the rules package for the syntax.
+type RuleConfig struct {
+	Name       string
+	Expression string
+
+	line int
+}
+
+// RoleConfig is a community role granted by a rule, see the roles package.
+type RoleConfig struct {
+	Name      string `yaml:"name" json:"name"`
+	Rule      string `yaml:"rule" json:"rule"`
+	HeldSince uint64 `yaml:"held_since" json:"held_since"`
+
+	line int
+}
+
+// File is a parsed collections config file.
+type File struct {
+	Path        string
+	Collections []CollectionConfig
+	Rules       []RuleConfig
+	Roles       []RoleConfig
+
+	// node is the top-level mapping, if the file has one, for unknown field errors.
+	node *yaml.Node
+}
+
+// LoadCollections reads and validates collections from a YAML or JSON file.
+func LoadCollections(path string) ([]holders.Collection, error) {
+	file, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return file.ToCollections(), nil
+}
+
+// LoadWeightedCollections reads and validates weighted collections from a YAML or JSON file.
+// Every collection must have a non-zero weight.
+func LoadWeightedCollections(path string) ([]holders.WeightedCollection, error) {
+	file, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.ValidateWeighted(); err != nil {
+		return nil, err
+	}
+	return file.ToWeightedCollections()
+}
+
+// Load parses a config file without validating it. The format is chosen by file extension.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := formatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	file.Path = path
+	return file, nil
+}
+
+// Parse parses config data. JSON is parsed as YAML after a syntax check so that line numbers are kept.
+func Parse(data []byte, format Format) (*File, error) {
+	if format == FormatJSON && !json.Valid(data) {
+		var v interface{}
+		err := json.Unmarshal(data, &v)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("line %d: %w", 1+bytes.Count(data[:min(syntaxErr.Offset, int64(len(data)))], []byte("\n")), err)
+		}
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	if len(root.Content) == 0 {
+		return file, nil
+	}
+
+	collectionsNode := root.Content[0]
+	if collectionsNode.Kind == yaml.MappingNode {
+		file.node = collectionsNode
+		if rulesNode := valueNode(collectionsNode, "rules"); rulesNode != nil {
+			rules, err := parseRules(rulesNode)
+			if err != nil {
+				return nil, err
+			}
+			file.Rules = rules
+		}
+		if rolesNode := valueNode(collectionsNode, "roles"); rolesNode != nil {
+			roles, err := parseRoles(rolesNode)
+			if err != nil {
+				return nil, err
+			}
+			file.Roles = roles
+		}
+		collectionsNode = valueNode(collectionsNode, "collections")
+		if collectionsNode == nil {
+			return nil, fmt.Errorf("line %d: missing \"collections\"", root.Content[0].Line)
+		}
+	}
+	if collectionsNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: collections must be a list", collectionsNode.Line)
+	}
+
+	for _, itemNode := range collectionsNode.Content {
+		var collection CollectionConfig
+		if err := itemNode.Decode(&collection); err != nil {
+			return nil, err
+		}
+		collection.node = itemNode
+		file.Collections = append(file.Collections, collection)
+	}
+
+	return file, nil
+}
+
+func parseRules(rulesNode *yaml.Node) ([]RuleConfig, error) {
+	if rulesNode.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: rules must be a mapping of rule name to expression", rulesNode.Line)
+	}
+	var rules []RuleConfig
+	for i := 0; i+1 < len(rulesNode.Content); i += 2 {
+		key, value := rulesNode.Content[i], rulesNode.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: rule %q must be an expression string", value.Line, key.Value)
+		}
+		rules = append(rules, RuleConfig{Name: key.Value, Expression: value.Value, line: value.Line})
+	}
+	return rules, nil
+}
+
+func parseRoles(rolesNode *yaml.Node) ([]RoleConfig, error) {
+	if rolesNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: roles must be a list", rolesNode.Line)
+	}
+	var roles []RoleConfig
+	for _, itemNode := range rolesNode.Content {
+		var role RoleConfig
+		if err := itemNode.Decode(&role); err != nil {
+			return nil, err
+		}
+		role.line = itemNode.Line
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// ParseRules parses the rules of the file by name. Call Validate first for errors with line numbers.
+func (f *File) ParseRules() (map[string]*rules.Rule, error) {
+	parsed := make(map[string]*rules.Rule)
+	for _, r := range f.Rules {
+		rule, err := rules.Parse(r.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		parsed[r.Name] = rule
+	}
+	return parsed, nil
+}
+
+// LoadRules reads, validates and parses the rules of a config file.
+func LoadRules(path string) (map[string]*rules.Rule, error) {
+	file, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return file.ParseRules()
+}
+
+// ToRoles parses the roles of the file. Call Validate first for errors with line numbers.
+func (f *File) ToRoles() ([]roles.Role, error) {
+	var result []roles.Role
+	for _, r := range f.Roles {
+		rule, err := rules.Parse(r.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("role %q: %w", r.Name, err)
+		}
+		result = append(result, roles.Role{Name: r.Name, Rule: rule, HeldSince: r.HeldSince})
+	}
+	return result, nil
+}
+
+// LoadRoles reads, validates and parses the roles of a config file.
+func LoadRoles(path string) ([]roles.Role, error) {
+	file, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return file.ToRoles()
+}
+
+func (f *File) ToCollections() []holders.Collection {
+	collections := make([]holders.Collection, len(f.Collections))
+	for i, c := range f.Collections {
+		collections[i] = c.toCollection(filepath.Dir(f.Path))
+	}
+	return collections
+}
+
+func (f *File) ToWeightedCollections() ([]holders.WeightedCollection, error) {
+	weightedCollections := make([]holders.WeightedCollection, len(f.Collections))
+	for i, c := range f.Collections {
+		weightedCollection, err := c.toWeightedCollection(filepath.Dir(f.Path))
+		if err != nil {
+			return nil, &ValidationError{Path: f.Path, Line: c.line("asset_weights_csv"), Collection: c.Name, Message: err.Error()}
+		}
+		weightedCollections[i] = weightedCollection
+	}
+	return weightedCollections, nil
+}
+
+// ToCollection converts the config to a collection. Relative asset_ids_file paths are resolved against the
+// working directory; use File.ToCollections to resolve them against the config file.
+func (c CollectionConfig) ToCollection() holders.Collection {
+	return c.toCollection("")
+}
+
+func (c CollectionConfig) toCollection(baseDir string) holders.Collection {
+	collection := holders.Collection{
+		Name:                    c.Name,
+		Addresses:               c.Addresses,
+		AssetIDs:                c.AssetIDs,
+		UnitNamePrefixes:        c.UnitNamePrefixes,
+		ExcludedAssets:          c.ExcludedAssets,
+		AssetIndexGreaterThan:   c.AssetIndexGreaterThan,
+		ExcludedHolderAddresses: c.ExcludedHolderAddresses,
+		IncludeNameContains:     c.IncludeNameContains,
+		ExcludeNameContains:     c.ExcludeNameContains,
+		MinimumBalance:          c.MinimumBalance,
+	}
+
+	var sources []holders.AssetSource
+	if c.AssetIDsFile != "" {
+		sources = append(sources, holders.FileAssetSource(resolvePath(baseDir, c.AssetIDsFile)))
+	}
+	if c.AssetIDsURL != "" {
+		sources = append(sources, holders.URLAssetSource(c.AssetIDsURL, nil))
+	}
+	if len(sources) > 0 {
+		collection.AssetSource = holders.MultiAssetSource(sources...)
+	}
+
+	return collection
+}
+
+func (c CollectionConfig) toWeightedCollection(baseDir string) (holders.WeightedCollection, error) {
+	weightedCollection := holders.WeightedCollection{
+		Collection: c.toCollection(baseDir),
+	}
+	if c.Weight != nil {
+		weightedCollection.Weight = *c.Weight
+	}
+	amountWeights, err := c.amountWeigher()
+	if err != nil {
+		return holders.WeightedCollection{}, err
+	}
+	weightedCollection.AmountWeights = amountWeights
+
+	assetWeights := holders.AssetWeightMap{}
+	if c.AssetWeightsCSV != "" {
+		csvWeights, err := holders.LoadAssetWeightsCSVFile(resolvePath(baseDir, c.AssetWeightsCSV))
+		if err != nil {
+			return holders.WeightedCollection{}, err
+		}
+		assetWeights = csvWeights
+	}
+	for key, weight := range c.AssetWeights {
+		assetID, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return holders.WeightedCollection{}, fmt.Errorf("invalid asset id %q", key)
+		}
+		assetWeights[assetID] = weight
+	}
+	if len(assetWeights) > 0 {
+		weightedCollection.AssetWeights = assetWeights
+	}
+
+	return weightedCollection, nil
+}
+
+const (
+	AmountWeightingRaw        = "raw"
+	AmountWeightingWholeToken = "whole_token"
+	AmountWeightingBracket    = "bracket"
+	AmountWeightingLog        = "log"
+)
+
+func (c CollectionConfig) amountWeigher() (holders.AmountWeigher, error) {
+	switch c.AmountWeighting {
+	case "", AmountWeightingRaw:
+		return nil, nil
+	case AmountWeightingWholeToken:
+		return holders.PerWholeToken, nil
+	case AmountWeightingBracket:
+		return holders.PerBracket(c.BracketSize), nil
+	case AmountWeightingLog:
+		return holders.LogScaled, nil
+	default:
+		return nil, fmt.Errorf("unknown amount_weighting %q", c.AmountWeighting)
+	}
+}
+
+// line returns the line of the value of the given key, or of the collection itself.
+func (c CollectionConfig) line(key string) int {
+	if c.node == nil {
+		return 0
+	}
+	if v := valueNode(c.node, key); v != nil {
+		return v.Line
+	}
+	return c.node.Line
+}
+
+// itemLine returns the line of the i-th element of the list under key.
+func (c CollectionConfig) itemLine(key string, i int) int {
+	if c.node == nil {
+		return 0
+	}
+	if v := valueNode(c.node, key); v != nil && v.Kind == yaml.SequenceNode && i < len(v.Content) {
+		return v.Content[i].Line
+	}
+	return c.line(key)
+}
+
+func valueNode(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// resolvePath resolves paths in a config file relative to the directory of the file
+func resolvePath(baseDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+func formatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", errors.New("unsupported config file extension: " + filepath.Ext(path))
+	}
+}
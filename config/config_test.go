@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/config"
+	"github.com/yellowbackground/holders/examples"
+	"github.com/yellowbackground/holders/testdata"
+	"testing"
+)
+
+func TestLoadCollections(t *testing.T) {
+	collections, err := config.LoadCollections("../examples/collections.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, examples.Collections, collections)
+}
+
+func TestLoadWeightedCollections(t *testing.T) {
+	weightedCollections, err := config.LoadWeightedCollections("../examples/collections.yaml")
+
+	assert.NoError(t, err)
+	assert.Len(t, weightedCollections, 5)
+	assert.Equal(t, uint64(6), weightedCollections[0].Weight)
+}
+
+func TestParseJSON(t *testing.T) {
+	file, err := config.Parse([]byte(`{"collections": [{"name": "A", "addresses": ["`+testdata.TestAccount1Address+`"], "weight": 2, "asset_weights": {"7": 10}}]}`), config.FormatJSON)
+	assert.NoError(t, err)
+	assert.NoError(t, file.ValidateWeighted())
+
+	weightedCollections, err := file.ToWeightedCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, holders.AssetWeightMap{7: 10}, weightedCollections[0].AssetWeights)
+}
+
+func TestParseJSONSyntaxError(t *testing.T) {
+	_, err := config.Parse([]byte("{\n  \"collections\": [\n    {\"name\": \"A\",}\n  ]\n}"), config.FormatJSON)
+	assert.EqualError(t, err, "line 3: invalid character '}' looking for beginning of object key string")
+}
+
+func TestValidate(t *testing.T) {
+	data := `colections: []
+collections:
+  - name: A
+    weight: 0
+    addresses:
+      - ` + testdata.TestAccount1Address + `
+      - NOTANADDRESS
+    include_name_contains: [Flamborghini]
+    exclude_name_contains: [flam]
+  - name: A
+    addresses: [` + testdata.TestAccount2Address + `]
+    wieght: 1
+`
+	file, err := config.Parse([]byte(data), config.FormatYAML)
+	assert.NoError(t, err)
+
+	err = file.ValidateWeighted()
+
+	var validationErrors config.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrors)
+	assert.Equal(t, `1: unknown top-level field "colections"
+4: collection "A": weight must be greater than zero
+7: collection "A": invalid address "NOTANADDRESS": decoded address is the wrong length, should be 36 bytes
+8: collection "A": include_name_contains "Flamborghini" is always excluded by exclude_name_contains "flam"
+10: collection "A": duplicate collection name, first defined at line 3
+10: collection "A": weight is required
+12: collection "A": unknown field "wieght"`, err.Error())
+}
+
+func TestRules(t *testing.T) {
+	data := `rules:
+  flambo owners: any "Yieldlings Flambos"
+  broken: any "Yieldlings" AND
+  unknown: any Flambo
+collections:
+  - name: Yieldlings Flambos
+    addresses: [` + testdata.TestAccount1Address + `]
+`
+	file, err := config.Parse([]byte(data), config.FormatYAML)
+	assert.NoError(t, err)
+
+	assert.EqualError(t, file.Validate(), `3: rule "broken": column 21: expected collection name, "any", "total", "NOT" or "(", got end of rule
+4: rule "unknown": unknown collection "Flambo"`)
+
+	file.Rules = file.Rules[:1]
+	parsedRules, err := file.ParseRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Yieldlings Flambos"}, parsedRules["flambo owners"].Collections())
+}
+
+func TestRoles(t *testing.T) {
+	data := `roles:
+  - name: OG
+    rule: any Yieldlings
+    held_since: 1000
+  - name: OG
+    rule: total >= 25
+  - name: Flambo Owner
+    rule: any Flambo
+collections:
+  - name: Yieldlings
+    addresses: [` + testdata.TestAccount1Address + `]
+`
+	file, err := config.Parse([]byte(data), config.FormatYAML)
+	assert.NoError(t, err)
+
+	assert.EqualError(t, file.Validate(), `5: role "OG": duplicate role name, first defined at line 2
+7: role "Flambo Owner": unknown collection "Flambo"`)
+
+	file.Roles = file.Roles[:1]
+	parsedRoles, err := file.ToRoles()
+	assert.NoError(t, err)
+	assert.Equal(t, "OG", parsedRoles[0].Name)
+	assert.Equal(t, uint64(1000), parsedRoles[0].HeldSince)
+	assert.Equal(t, []string{"Yieldlings"}, parsedRoles[0].Rule.Collections())
+}
+
+func TestToCollection(t *testing.T) {
+	collectionConfig := config.CollectionConfig{Name: "Yieldlings", Addresses: []string{testdata.TestAccount1Address}, UnitNamePrefixes: []string{"YLDY"}}
+
+	collection := collectionConfig.ToCollection()
+
+	assert.Equal(t, "Yieldlings", collection.Name)
+	assert.Equal(t, []string{testdata.TestAccount1Address}, collection.Addresses)
+	assert.Equal(t, []string{"YLDY"}, collection.UnitNamePrefixes)
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders/rules"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var knownFields = map[string]bool{
+	"name":                      true,
+	"addresses":                 true,
+	"asset_ids":                 true,
+	"asset_ids_file":            true,
+	"asset_ids_url":             true,
+	"unit_name_prefixes":        true,
+	"excluded_assets":           true,
+	"asset_index_greater_than":  true,
+	"excluded_holder_addresses": true,
+	"include_name_contains":     true,
+	"exclude_name_contains":     true,
+	"minimum_balance":           true,
+	"weight":                    true,
+	"amount_weighting":          true,
+	"bracket_size":              true,
+	"asset_weights":             true,
+	"asset_weights_csv":         true,
+}
+
+// knownTopLevelFields are the fields of the mapping at the top of a config file.
+var knownTopLevelFields = map[string]bool{
+	"collections": true,
+	"rules":       true,
+	"roles":       true,
+}
+
+type ValidationError struct {
+	Path       string
+	Line       int
+	Collection string
+	Message    string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(":")
+	}
+	if e.Line > 0 {
+		b.WriteString(strconv.Itoa(e.Line))
+		b.WriteString(":")
+	}
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	if e.Collection != "" {
+		fmt.Fprintf(&b, "collection %q: ", e.Collection)
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// ValidationErrors holds every problem found in a config file, ordered by line.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate reports all problems with the collections at once.
+func (f *File) Validate() error {
+	return f.validate(false)
+}
+
+// ValidateWeighted is Validate with the additional requirement that every collection has a non-zero weight.
+func (f *File) ValidateWeighted() error {
+	return f.validate(true)
+}
+
+func (f *File) validate(weighted bool) error {
+	var errs ValidationErrors
+	addError := func(c CollectionConfig, line int, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			Path:       f.Path,
+			Line:       line,
+			Collection: c.Name,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if f.node != nil {
+		for i := 0; i+1 < len(f.node.Content); i += 2 {
+			if key := f.node.Content[i]; !knownTopLevelFields[key.Value] {
+				addError(CollectionConfig{}, key.Line, "unknown top-level field %q", key.Value)
+			}
+		}
+	}
+
+	firstDefinedAt := make(map[string]int)
+	for _, c := range f.Collections {
+		if c.node != nil {
+			for i := 0; i+1 < len(c.node.Content); i += 2 {
+				if key := c.node.Content[i]; !knownFields[key.Value] {
+					addError(c, key.Line, "unknown field %q", key.Value)
+				}
+			}
+		}
+
+		if strings.TrimSpace(c.Name) == "" {
+			addError(c, c.line("name"), "name is required")
+		} else if line, found := firstDefinedAt[c.Name]; found {
+			addError(c, c.line("name"), "duplicate collection name, first defined at line %d", line)
+		} else {
+			firstDefinedAt[c.Name] = c.line("name")
+		}
+
+		if len(c.Addresses) == 0 && len(c.AssetIDs) == 0 && c.AssetIDsFile == "" && c.AssetIDsURL == "" {
+			addError(c, c.line("addresses"), "addresses, asset_ids, asset_ids_file or asset_ids_url is required")
+		}
+		for i, address := range c.Addresses {
+			if _, err := types.DecodeAddress(address); err != nil {
+				addError(c, c.itemLine("addresses", i), "invalid address %q: %v", address, err)
+			}
+		}
+		for i, address := range c.ExcludedHolderAddresses {
+			if _, err := types.DecodeAddress(address); err != nil {
+				addError(c, c.itemLine("excluded_holder_addresses", i), "invalid excluded holder address %q: %v", address, err)
+			}
+		}
+
+		for i, include := range c.IncludeNameContains {
+			for _, exclude := range c.ExcludeNameContains {
+				if strings.Contains(strings.ToLower(include), strings.ToLower(exclude)) {
+					addError(c, c.itemLine("include_name_contains", i), "include_name_contains %q is always excluded by exclude_name_contains %q", include, exclude)
+				}
+			}
+		}
+
+		if weighted {
+			if c.Weight == nil {
+				addError(c, c.line("weight"), "weight is required")
+			} else if *c.Weight == 0 {
+				addError(c, c.line("weight"), "weight must be greater than zero")
+			}
+		}
+		if c.MinimumBalance < 0 {
+			addError(c, c.line("minimum_balance"), "minimum_balance must not be negative")
+		}
+		if _, err := c.amountWeigher(); err != nil {
+			addError(c, c.line("amount_weighting"), "%v", err)
+		}
+		if c.AmountWeighting == AmountWeightingBracket && c.BracketSize <= 0 {
+			addError(c, c.line("bracket_size"), "bracket_size must be greater than zero for bracket amount_weighting")
+		}
+		for key := range c.AssetWeights {
+			if _, err := strconv.ParseUint(key, 10, 64); err != nil {
+				addError(c, c.line("asset_weights"), "invalid asset id %q in asset_weights", key)
+			}
+		}
+	}
+
+	var collectionNames []string
+	for _, c := range f.Collections {
+		collectionNames = append(collectionNames, c.Name)
+	}
+	ruleDefinedAt := make(map[string]int)
+	for _, r := range f.Rules {
+		addRuleError := func(format string, args ...interface{}) {
+			errs = append(errs, &ValidationError{
+				Path:    f.Path,
+				Line:    r.line,
+				Message: fmt.Sprintf("rule %q: ", r.Name) + fmt.Sprintf(format, args...),
+			})
+		}
+		if line, found := ruleDefinedAt[r.Name]; found {
+			addRuleError("duplicate rule name, first defined at line %d", line)
+		} else {
+			ruleDefinedAt[r.Name] = r.line
+		}
+		rule, err := rules.Parse(r.Expression)
+		if err != nil {
+			addRuleError("%v", err)
+			continue
+		}
+		if err := rule.Validate(collectionNames); err != nil {
+			addRuleError("%v", err)
+		}
+	}
+
+	roleDefinedAt := make(map[string]int)
+	for _, r := range f.Roles {
+		addRoleError := func(format string, args ...interface{}) {
+			errs = append(errs, &ValidationError{
+				Path:    f.Path,
+				Line:    r.line,
+				Message: fmt.Sprintf("role %q: ", r.Name) + fmt.Sprintf(format, args...),
+			})
+		}
+		if r.Name == "" {
+			addRoleError("name is required")
+		} else if line, found := roleDefinedAt[r.Name]; found {
+			addRoleError("duplicate role name, first defined at line %d", line)
+		} else {
+			roleDefinedAt[r.Name] = r.line
+		}
+		rule, err := rules.Parse(r.Rule)
+		if err != nil {
+			addRoleError("%v", err)
+			continue
+		}
+		if err := rule.Validate(collectionNames); err != nil {
+			addRoleError("%v", err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
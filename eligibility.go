@@ -0,0 +1,197 @@
+package holders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const microAlgosPerAlgo = 1000000
+
+// AccountState is the state of an account used to decide if its holder may enter a raffle.
+type AccountState struct {
+	Address        string
+	Balance        uint64
+	CreatedAtRound uint64
+	CreatedAt      time.Time
+	OptedInAssets  uint64
+	Participating  bool
+	Closed         bool
+}
+
+type AccountStateClient interface {
+	GetAccountState(ctx context.Context, address string) (AccountState, error)
+}
+
+// EligibilityRule checks a single property of an account. The reason explains the verdict.
+type EligibilityRule interface {
+	Name() string
+	Check(state AccountState) (passed bool, reason string)
+}
+
+type eligibilityRule struct {
+	name  string
+	check func(state AccountState) (bool, string)
+}
+
+func (r eligibilityRule) Name() string {
+	return r.name
+}
+
+func (r eligibilityRule) Check(state AccountState) (bool, string) {
+	return r.check(state)
+}
+
+// EligibilityRuleFunc creates a named EligibilityRule from any check of AccountState.
+func EligibilityRuleFunc(name string, check func(state AccountState) (bool, string)) EligibilityRule {
+	return eligibilityRule{name: name, check: check}
+}
+
+// MinimumAlgoBalance requires a balance of at least microAlgos.
+func MinimumAlgoBalance(microAlgos uint64) EligibilityRule {
+	return EligibilityRuleFunc("MinimumAlgoBalance", func(state AccountState) (bool, string) {
+		return state.Balance >= microAlgos, fmt.Sprintf("balance %s ALGO, minimum %s ALGO", formatAlgos(state.Balance), formatAlgos(microAlgos))
+	})
+}
+
+// MinimumAccountAge requires the account to have been created at least age ago.
+func MinimumAccountAge(age time.Duration) EligibilityRule {
+	return EligibilityRuleFunc("MinimumAccountAge", func(state AccountState) (bool, string) {
+		accountAge := time.Since(state.CreatedAt)
+		return accountAge >= age, fmt.Sprintf("created at round %d, %s ago, minimum %s", state.CreatedAtRound, accountAge.Round(time.Hour), age)
+	})
+}
+
+// CreatedBeforeRound requires the account to have been created before round.
+func CreatedBeforeRound(round uint64) EligibilityRule {
+	return EligibilityRuleFunc("CreatedBeforeRound", func(state AccountState) (bool, string) {
+		return state.CreatedAtRound < round, fmt.Sprintf("created at round %d, required before %d", state.CreatedAtRound, round)
+	})
+}
+
+func MinimumOptedInAssets(count uint64) EligibilityRule {
+	return EligibilityRuleFunc("MinimumOptedInAssets", func(state AccountState) (bool, string) {
+		return state.OptedInAssets >= count, fmt.Sprintf("opted into %d assets, minimum %d", state.OptedInAssets, count)
+	})
+}
+
+// RequireParticipating requires the account to be online in consensus.
+func RequireParticipating() EligibilityRule {
+	return EligibilityRuleFunc("RequireParticipating", func(state AccountState) (bool, string) {
+		if state.Participating {
+			return true, "participating in consensus"
+		}
+		return false, "not participating in consensus"
+	})
+}
+
+func RequireNotClosed() EligibilityRule {
+	return EligibilityRuleFunc("RequireNotClosed", func(state AccountState) (bool, string) {
+		if state.Closed {
+			return false, "account is closed"
+		}
+		return true, "account is open"
+	})
+}
+
+type RuleResult struct {
+	Rule   string
+	Passed bool
+	Reason string
+}
+
+type EligibilityResult struct {
+	Address  string
+	Eligible bool
+	Rules    []RuleResult
+}
+
+// CheckEligibility applies every rule to each address. An address is eligible when it passes all rules.
+func CheckEligibility(ctx context.Context, client AccountStateClient, addresses []string, rules []EligibilityRule, concurrency int) (map[string]EligibilityResult, error) {
+	result := make(map[string]EligibilityResult)
+	resultMutex := &sync.Mutex{}
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	errChan := make(chan error, len(addresses))
+
+	for _, address := range addresses {
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func(address string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			state, err := client.GetAccountState(ctx, address)
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			resultMutex.Lock()
+			result[address] = ApplyEligibilityRules(state, rules)
+			resultMutex.Unlock()
+		}(address)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	if len(errChan) > 0 {
+		return nil, <-errChan
+	}
+
+	return result, nil
+}
+
+func ApplyEligibilityRules(state AccountState, rules []EligibilityRule) EligibilityResult {
+	result := EligibilityResult{
+		Address:  state.Address,
+		Eligible: true,
+	}
+	for _, rule := range rules {
+		passed, reason := rule.Check(state)
+		result.Rules = append(result.Rules, RuleResult{
+			Rule:   rule.Name(),
+			Passed: passed,
+			Reason: reason,
+		})
+		result.Eligible = result.Eligible && passed
+	}
+	return result
+}
+
+// GetHolderAddresses returns the unique holder addresses across all collections.
+func GetHolderAddresses(holdingsByCollection map[string][]AssetHolding) []string {
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, holdings := range holdingsByCollection {
+		for _, holding := range holdings {
+			if !seen[holding.Address] {
+				seen[holding.Address] = true
+				addresses = append(addresses, holding.Address)
+			}
+		}
+	}
+	return addresses
+}
+
+// FilterEligibleHoldings removes the holdings of addresses that are not eligible.
+func FilterEligibleHoldings(holdingsByCollection map[string][]AssetHolding, results map[string]EligibilityResult) map[string][]AssetHolding {
+	filtered := make(map[string][]AssetHolding)
+	for collectionName, holdings := range holdingsByCollection {
+		filtered[collectionName] = []AssetHolding{}
+		for _, holding := range holdings {
+			if results[holding.Address].Eligible {
+				filtered[collectionName] = append(filtered[collectionName], holding)
+			}
+		}
+	}
+	return filtered
+}
+
+func formatAlgos(microAlgos uint64) string {
+	return fmt.Sprintf("%d.%06d", microAlgos/microAlgosPerAlgo, microAlgos%microAlgosPerAlgo)
+}
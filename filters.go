@@ -0,0 +1,169 @@
+package holders
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AssetFilter decides whether an asset created by a collection's addresses is part of the collection.
+type AssetFilter interface {
+	Name() string
+	Match(asset Asset) bool
+}
+
+type assetFilter struct {
+	name  string
+	match func(asset Asset) bool
+}
+
+func (f assetFilter) Name() string {
+	return f.name
+}
+
+func (f assetFilter) Match(asset Asset) bool {
+	return f.match(asset)
+}
+
+// FilterFunc creates a named AssetFilter from any function of Asset.
+func FilterFunc(name string, match func(asset Asset) bool) AssetFilter {
+	return assetFilter{name: name, match: match}
+}
+
+// And matches assets that match all the filters.
+func And(filters ...AssetFilter) AssetFilter {
+	return FilterFunc(combinedName("And", filters), func(asset Asset) bool {
+		for _, filter := range filters {
+			if !filter.Match(asset) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or matches assets that match any of the filters.
+func Or(filters ...AssetFilter) AssetFilter {
+	return FilterFunc(combinedName("Or", filters), func(asset Asset) bool {
+		for _, filter := range filters {
+			if filter.Match(asset) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func Not(filter AssetFilter) AssetFilter {
+	return FilterFunc("Not("+filter.Name()+")", func(asset Asset) bool {
+		return !filter.Match(asset)
+	})
+}
+
+func NameMatches(pattern *regexp.Regexp) AssetFilter {
+	return FilterFunc(fmt.Sprintf("NameMatches(%s)", pattern), func(asset Asset) bool {
+		return pattern.MatchString(asset.Name)
+	})
+}
+
+func UnitNameMatches(pattern *regexp.Regexp) AssetFilter {
+	return FilterFunc(fmt.Sprintf("UnitNameMatches(%s)", pattern), func(asset Asset) bool {
+		return pattern.MatchString(asset.UnitName)
+	})
+}
+
+// AssetIDBetween matches asset IDs from min to max inclusive.
+func AssetIDBetween(min uint64, max uint64) AssetFilter {
+	return FilterFunc(fmt.Sprintf("AssetIDBetween(%d, %d)", min, max), func(asset Asset) bool {
+		return asset.AssetID >= min && asset.AssetID <= max
+	})
+}
+
+func AssetIDIn(assetIDs ...uint64) AssetFilter {
+	return FilterFunc(fmt.Sprintf("AssetIDIn(%v)", assetIDs), func(asset Asset) bool {
+		return containsUint64(assetIDs, asset.AssetID)
+	})
+}
+
+func URLHasPrefix(prefix string) AssetFilter {
+	return FilterFunc(fmt.Sprintf("URLHasPrefix(%s)", prefix), func(asset Asset) bool {
+		return strings.HasPrefix(asset.URL, prefix)
+	})
+}
+
+// TotalBetween matches assets whose total supply in base units is from min to max inclusive.
+func TotalBetween(min uint64, max uint64) AssetFilter {
+	return FilterFunc(fmt.Sprintf("TotalBetween(%d, %d)", min, max), func(asset Asset) bool {
+		return asset.Total >= min && asset.Total <= max
+	})
+}
+
+func DecimalsEqual(decimals uint64) AssetFilter {
+	return FilterFunc(fmt.Sprintf("DecimalsEqual(%d)", decimals), func(asset Asset) bool {
+		return asset.Decimals == decimals
+	})
+}
+
+// AssetFilters translates the collection fields into filters, followed by the collection's own Filters.
+// The field filters are always present, and match every asset when their field is not set.
+func (c Collection) AssetFilters() []AssetFilter {
+	unitNamePrefixes := c.UnitNamePrefixes
+	excludedAssets := c.ExcludedAssets
+	assetIndexGreaterThan := c.AssetIndexGreaterThan
+	includeNameContains := c.IncludeNameContains
+	excludeNameContains := c.ExcludeNameContains
+	filters := []AssetFilter{
+		FilterFunc("UnitNamePrefixes", func(asset Asset) bool {
+			return len(unitNamePrefixes) == 0 || matchesUnitNamePrefix(unitNamePrefixes, asset.UnitName)
+		}),
+		FilterFunc("ExcludedAssets", func(asset Asset) bool {
+			return !containsUint64(excludedAssets, asset.AssetID)
+		}),
+		FilterFunc("AssetIndexGreaterThan", func(asset Asset) bool {
+			return asset.AssetID > assetIndexGreaterThan
+		}),
+		FilterFunc("IncludeNameContains", func(asset Asset) bool {
+			return len(includeNameContains) == 0 || nameContains(includeNameContains, asset.Name)
+		}),
+		FilterFunc("ExcludeNameContains", func(asset Asset) bool {
+			return !nameContains(excludeNameContains, asset.Name)
+		}),
+	}
+	return append(filters, c.Filters...)
+}
+
+// Matches reports whether the asset passes every filter of the collection.
+func (c Collection) Matches(asset Asset) bool {
+	for _, filter := range c.AssetFilters() {
+		if !filter.Match(asset) {
+			return false
+		}
+	}
+	return true
+}
+
+func combinedName(operator string, filters []AssetFilter) string {
+	names := make([]string, len(filters))
+	for i, filter := range filters {
+		names[i] = filter.Name()
+	}
+	return operator + "(" + strings.Join(names, ", ") + ")"
+}
+
+func matchesUnitNamePrefix(unitNamePrefixes []string, unitName string) bool {
+	for _, prefix := range unitNamePrefixes {
+		if strings.HasPrefix(strings.TrimSpace(unitName), strings.TrimSpace(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func nameContains(nameContains []string, name string) bool {
+	for _, nameContainsString := range nameContains {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(nameContainsString)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package holders
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestAssetFilters(t *testing.T) {
+	mfer := Asset{Name: "Mostly Fren #001", UnitName: "MFER001", AssetID: 10, URL: "ipfs://abc", Total: 1}
+	burned := Asset{Name: "Mostly Fren Burned", UnitName: "MFER002", AssetID: 20, URL: "https://x", Total: 1}
+	token := Asset{Name: "Fren Token", UnitName: "FREN", AssetID: 30, Total: 1000000, Decimals: 6}
+
+	tests := map[string]struct {
+		GotFilter AssetFilter
+		WantMatch map[uint64]bool
+	}{
+		"unit name matches": {
+			GotFilter: UnitNameMatches(regexp.MustCompile(`^MFER\d{3}$`)),
+			WantMatch: map[uint64]bool{10: true, 20: true, 30: false},
+		},
+		"asset id between": {
+			GotFilter: AssetIDBetween(10, 20),
+			WantMatch: map[uint64]bool{10: true, 20: true, 30: false},
+		},
+		"name does not end with burned": {
+			GotFilter: Not(NameMatches(regexp.MustCompile(`Burned$`))),
+			WantMatch: map[uint64]bool{10: true, 20: false, 30: true},
+		},
+		"or group": {
+			GotFilter: Or(And(URLHasPrefix("ipfs://"), TotalBetween(1, 1)), DecimalsEqual(6)),
+			WantMatch: map[uint64]bool{10: true, 20: false, 30: true},
+		},
+		"asset id in": {
+			GotFilter: AssetIDIn(20, 30),
+			WantMatch: map[uint64]bool{10: false, 20: true, 30: true},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotMatch := map[uint64]bool{}
+			for _, asset := range []Asset{mfer, burned, token} {
+				gotMatch[asset.AssetID] = test.GotFilter.Match(asset)
+			}
+			assert.Equal(t, test.WantMatch, gotMatch)
+		})
+	}
+}
+
+func TestCollectionAssetFilters(t *testing.T) {
+	collection := Collection{
+		UnitNamePrefixes:    []string{"MFER"},
+		ExcludeNameContains: []string{"burned"},
+		Filters:             []AssetFilter{AssetIDBetween(1, 15)},
+	}
+
+	var names []string
+	for _, filter := range collection.AssetFilters() {
+		names = append(names, filter.Name())
+	}
+
+	assert.Equal(t, []string{"UnitNamePrefixes", "ExcludedAssets", "AssetIndexGreaterThan", "IncludeNameContains", "ExcludeNameContains", "AssetIDBetween(1, 15)"}, names)
+	assert.True(t, collection.Matches(Asset{Name: "Fren", UnitName: "MFER001", AssetID: 10}))
+	assert.False(t, collection.Matches(Asset{Name: "Fren Burned", UnitName: "MFER001", AssetID: 10}))
+	assert.False(t, collection.Matches(Asset{Name: "Fren", UnitName: "MFER001", AssetID: 16}))
+}
+
+func TestAssetExplanationJSON(t *testing.T) {
+	explanation := AssetExplanation{
+		Asset:    Asset{Name: "Fren #1", UnitName: "MFER001", AssetID: 1},
+		Creator:  "CREATOR",
+		Filters:  []FilterVerdict{{Filter: "UnitNamePrefixes", Passed: true}},
+		Included: true,
+	}
+
+	data, err := json.Marshal(explanation)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"asset": {"Name": "Fren #1", "UnitName": "MFER001", "AssetID": 1, "URL": "", "Total": 0, "Decimals": 0},
+		"creator": "CREATOR",
+		"filters": [{"filter": "UnitNamePrefixes", "passed": true}],
+		"included": true
+	}`, string(data))
+}
@@ -0,0 +1,221 @@
+// Package gate lets a visitor prove that they control an address that holds a collection, for token gated
+// channels and pages. The visitor signs a challenge, and gets back a short-lived token listing the collections they hold.
+package gate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChallengeTTL  = 5 * time.Minute
+	defaultTokenTTL      = time.Hour
+	defaultMaxChallenges = 10000
+)
+
+var (
+	// ErrUnknownChallenge is returned for nonces that were never issued, or have already been answered.
+	ErrUnknownChallenge = errors.New("unknown challenge")
+	ErrChallengeExpired = errors.New("challenge expired")
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrNotHolder is returned when the address has proved control but holds none of the collections.
+	ErrNotHolder = errors.New("address holds none of the collections")
+	// ErrTooManyChallenges is returned when MaxChallenges unexpired challenges are waiting for an answer.
+	ErrTooManyChallenges = errors.New("too many outstanding challenges")
+)
+
+// Challenge is answered by signing Message with the key of Address, either as bytes or as the note of a
+// zero ALGO payment from Address to itself that can't be sent. Each challenge can only be answered once.
+type Challenge struct {
+	Address   string
+	Nonce     string
+	Message   string
+	ExpiresAt time.Time
+}
+
+type Options struct {
+	// Service is the domain or name of the service the tokens are for. It is part of every challenge message,
+	// so a signature asked for by another service can't be used here. Required.
+	Service     string
+	Collections []holders.Collection
+	// ChallengeTTL defaults to 5 minutes.
+	ChallengeTTL time.Duration
+	// TokenTTL defaults to 1 hour.
+	TokenTTL time.Duration
+	// MaxChallenges is how many unexpired challenges can wait for an answer. Defaults to 10000.
+	MaxChallenges int
+}
+
+// Gate issues challenges and exchanges signed answers for tokens. It is safe for concurrent use.
+type Gate struct {
+	lookup     *holders.HoldingsLookup
+	authClient holders.AuthAddressClient
+	key        ed25519.PrivateKey
+	options    Options
+
+	mutex      sync.Mutex
+	challenges map[string]Challenge
+}
+
+// New creates a gate that signs tokens with key. Rekeyed accounts must answer with the key they are rekeyed to.
+// Holdings are checked against collection assets that may be as old as lookup.TTL allows.
+func New(lookup *holders.HoldingsLookup, authClient holders.AuthAddressClient, key ed25519.PrivateKey, options Options) *Gate {
+	if options.ChallengeTTL == 0 {
+		options.ChallengeTTL = defaultChallengeTTL
+	}
+	if options.TokenTTL == 0 {
+		options.TokenTTL = defaultTokenTTL
+	}
+	if options.MaxChallenges == 0 {
+		options.MaxChallenges = defaultMaxChallenges
+	}
+	return &Gate{
+		lookup:     lookup,
+		authClient: authClient,
+		key:        key,
+		options:    options,
+		challenges: make(map[string]Challenge),
+	}
+}
+
+// Challenge issues a new challenge for address.
+func (g *Gate) Challenge(address string) (Challenge, error) {
+	if g.options.Service == "" {
+		return Challenge{}, errors.New("gate service must be set")
+	}
+	if _, err := types.DecodeAddress(address); err != nil {
+		return Challenge{}, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return Challenge{}, err
+	}
+
+	now := time.Now()
+	challenge := Challenge{
+		Address:   address,
+		Nonce:     hex.EncodeToString(nonce),
+		ExpiresAt: now.Add(g.options.ChallengeTTL).UTC(),
+	}
+	challenge.Message = fmt.Sprintf("%s asks you to prove that %s holds the collection. Nonce: %s", g.options.Service, challenge.Address, challenge.Nonce)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for nonce, issued := range g.challenges {
+		if now.After(issued.ExpiresAt) {
+			delete(g.challenges, nonce)
+		}
+	}
+	if len(g.challenges) >= g.options.MaxChallenges {
+		return Challenge{}, ErrTooManyChallenges
+	}
+	g.challenges[challenge.Nonce] = challenge
+	return challenge, nil
+}
+
+// VerifyMessage answers a challenge with an ed25519 signature of its message, signed the way Algorand wallets sign bytes.
+func (g *Gate) VerifyMessage(ctx context.Context, nonce string, signature []byte) (string, Claims, error) {
+	return g.answer(ctx, nonce, func(challenge Challenge, signer ed25519.PublicKey) error {
+		if !crypto.VerifyBytes(signer, []byte(challenge.Message), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	})
+}
+
+// VerifyTransaction answers a challenge with a msgpack encoded signed transaction: a zero ALGO payment from the
+// address to itself with the message as its note. Its last valid round must be before its first valid round,
+// so the network rejects it and nobody who sees the answer can make the address pay its fee.
+func (g *Gate) VerifyTransaction(ctx context.Context, nonce string, signedTxn []byte) (string, Claims, error) {
+	var stx types.SignedTxn
+	if err := msgpack.Decode(signedTxn, &stx); err != nil {
+		return "", Claims{}, fmt.Errorf("decoding signed transaction: %w", err)
+	}
+
+	return g.answer(ctx, nonce, func(challenge Challenge, signer ed25519.PublicKey) error {
+		txn := stx.Txn
+		switch {
+		case txn.Type != types.PaymentTx || txn.Amount != 0:
+			return errors.New("transaction must be a zero ALGO payment")
+		case txn.Sender.String() != challenge.Address || txn.Receiver != txn.Sender:
+			return errors.New("transaction must be sent by the address to itself")
+		case !txn.CloseRemainderTo.IsZero() || !txn.RekeyTo.IsZero():
+			return errors.New("transaction must not close or rekey the account")
+		case txn.LastValid >= txn.FirstValid:
+			return errors.New("transaction last valid round must be before its first valid round, so it can't be sent")
+		case !bytes.Equal(txn.Note, []byte(challenge.Message)):
+			return errors.New("transaction note must be the challenge message")
+		case !stx.Msig.Blank() || !stx.Lsig.Blank():
+			return errors.New("transaction must be signed by a single key")
+		}
+		toSign := append([]byte("TX"), msgpack.Encode(txn)...)
+		if !ed25519.Verify(signer, toSign, stx.Sig[:]) {
+			return ErrInvalidSignature
+		}
+		return nil
+	})
+}
+
+// answer uses up the challenge, checks the signature with the key currently authorized for the address
+// and issues a token for the collections it holds.
+func (g *Gate) answer(ctx context.Context, nonce string, verify func(challenge Challenge, signer ed25519.PublicKey) error) (string, Claims, error) {
+	g.mutex.Lock()
+	challenge, found := g.challenges[nonce]
+	delete(g.challenges, nonce)
+	g.mutex.Unlock()
+	if !found {
+		return "", Claims{}, ErrUnknownChallenge
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return "", Claims{}, ErrChallengeExpired
+	}
+
+	authAddress, err := g.authClient.AuthAddress(ctx, challenge.Address)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	signer, err := types.DecodeAddress(authAddress)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	if err := verify(challenge, signer[:]); err != nil {
+		return "", Claims{}, err
+	}
+
+	holdings, err := g.lookup.GetHoldingsByAddress(ctx, challenge.Address, g.options.Collections)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	if len(holdings) == 0 {
+		return "", Claims{}, ErrNotHolder
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	claims := Claims{
+		Address:   challenge.Address,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(g.options.TokenTTL),
+	}
+	for collectionName := range holdings {
+		claims.Collections = append(claims.Collections, collectionName)
+	}
+	sort.Strings(claims.Collections)
+
+	token, err := IssueToken(claims, g.key)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	return token, claims, nil
+}
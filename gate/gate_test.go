@@ -0,0 +1,225 @@
+package gate_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/gate"
+	"github.com/yellowbackground/holders/testdata"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	holder  = crypto.GenerateAccount()
+	rekeyed = crypto.GenerateAccount()
+	visitor = crypto.GenerateAccount()
+	issuer  = crypto.GenerateAccount()
+)
+
+func newGate(options gate.Options) *gate.Gate {
+	client := &testdata.FakeCollectionClient{
+		Assets: map[string][]holders.Asset{
+			"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}},
+			"Best Frens":   {{AssetID: 2, UnitName: "BFER001"}},
+		},
+		AccountAssets: map[string][]holders.AccountAsset{
+			holder.Address.String():  {{AssetID: 1, Amount: 1}, {AssetID: 2, Amount: 1}},
+			rekeyed.Address.String(): {{AssetID: 2, Amount: 1}},
+		},
+		// rekeyed is controlled by the key of holder
+		AuthAddresses: map[string]string{rekeyed.Address.String(): holder.Address.String()},
+	}
+	if options.Service == "" {
+		options.Service = "frens.example"
+	}
+	options.Collections = []holders.Collection{{Name: "Mostly Frens"}, {Name: "Best Frens"}}
+	return gate.New(holders.NewHoldingsLookup(client, client), client, issuer.PrivateKey, options)
+}
+
+func signMessage(t *testing.T, key ed25519.PrivateKey, challenge gate.Challenge) []byte {
+	signature, err := crypto.SignBytes(key, []byte(challenge.Message))
+	assert.NoError(t, err)
+	return signature
+}
+
+func TestVerifyMessage(t *testing.T) {
+	tests := map[string]struct {
+		Address         types.Address
+		Key             ed25519.PrivateKey
+		WantCollections []string
+		WantErr         error
+	}{
+		"holder": {
+			Address:         holder.Address,
+			Key:             holder.PrivateKey,
+			WantCollections: []string{"Best Frens", "Mostly Frens"},
+		},
+		"signed with another key": {
+			Address: holder.Address,
+			Key:     visitor.PrivateKey,
+			WantErr: gate.ErrInvalidSignature,
+		},
+		"rekeyed account signed with its auth key": {
+			Address:         rekeyed.Address,
+			Key:             holder.PrivateKey,
+			WantCollections: []string{"Best Frens"},
+		},
+		"rekeyed account signed with its own key": {
+			Address: rekeyed.Address,
+			Key:     rekeyed.PrivateKey,
+			WantErr: gate.ErrInvalidSignature,
+		},
+		"not a holder": {
+			Address: visitor.Address,
+			Key:     visitor.PrivateKey,
+			WantErr: gate.ErrNotHolder,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			underTest := newGate(gate.Options{})
+			challenge, err := underTest.Challenge(test.Address.String())
+			assert.NoError(t, err)
+
+			token, claims, err := underTest.VerifyMessage(context.Background(), challenge.Nonce, signMessage(t, test.Key, challenge))
+
+			assert.ErrorIs(t, err, test.WantErr)
+			if test.WantErr != nil {
+				return
+			}
+			assert.Equal(t, test.Address.String(), claims.Address)
+			assert.Equal(t, test.WantCollections, claims.Collections)
+			verified, err := gate.VerifyToken(token, issuer.PublicKey, time.Now())
+			assert.NoError(t, err)
+			assert.Equal(t, claims, verified)
+		})
+	}
+}
+
+func TestChallengeCanOnlyBeAnsweredOnce(t *testing.T) {
+	underTest := newGate(gate.Options{})
+	challenge, err := underTest.Challenge(holder.Address.String())
+	assert.NoError(t, err)
+	signature := signMessage(t, holder.PrivateKey, challenge)
+
+	_, _, err = underTest.VerifyMessage(context.Background(), challenge.Nonce, signature)
+	assert.NoError(t, err)
+	_, _, err = underTest.VerifyMessage(context.Background(), challenge.Nonce, signature)
+	assert.ErrorIs(t, err, gate.ErrUnknownChallenge)
+}
+
+func TestChallengeExpires(t *testing.T) {
+	underTest := newGate(gate.Options{ChallengeTTL: time.Nanosecond})
+	challenge, err := underTest.Challenge(holder.Address.String())
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	_, _, err = underTest.VerifyMessage(context.Background(), challenge.Nonce, signMessage(t, holder.PrivateKey, challenge))
+
+	assert.ErrorIs(t, err, gate.ErrChallengeExpired)
+}
+
+func TestChallengeNamesService(t *testing.T) {
+	_, err := gate.New(nil, nil, issuer.PrivateKey, gate.Options{}).Challenge(holder.Address.String())
+	assert.EqualError(t, err, "gate service must be set")
+
+	underTest := newGate(gate.Options{})
+	challenge, err := underTest.Challenge(holder.Address.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "frens.example asks you to prove that "+holder.Address.String()+" holds the collection. Nonce: "+challenge.Nonce, challenge.Message)
+
+	// a signature of another service's challenge, with the same nonce, doesn't answer it
+	other := challenge
+	other.Message = strings.Replace(challenge.Message, "frens.example", "other.example", 1)
+	_, _, err = underTest.VerifyMessage(context.Background(), challenge.Nonce, signMessage(t, holder.PrivateKey, other))
+	assert.ErrorIs(t, err, gate.ErrInvalidSignature)
+}
+
+func TestTooManyChallenges(t *testing.T) {
+	underTest := newGate(gate.Options{MaxChallenges: 1})
+	_, err := underTest.Challenge(holder.Address.String())
+	assert.NoError(t, err)
+
+	_, err = underTest.Challenge(holder.Address.String())
+	assert.ErrorIs(t, err, gate.ErrTooManyChallenges)
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	underTest := newGate(gate.Options{})
+
+	tests := map[string]struct {
+		Modify  func(txn *types.Transaction)
+		WantErr string
+	}{
+		"zero payment to self": {},
+		"payment to another address": {
+			Modify:  func(txn *types.Transaction) { txn.Receiver = visitor.Address },
+			WantErr: "transaction must be sent by the address to itself",
+		},
+		"rekey": {
+			Modify:  func(txn *types.Transaction) { txn.RekeyTo = visitor.Address },
+			WantErr: "transaction must not close or rekey the account",
+		},
+		"can be sent": {
+			Modify:  func(txn *types.Transaction) { txn.LastValid = 2000 },
+			WantErr: "transaction last valid round must be before its first valid round, so it can't be sent",
+		},
+		"another note": {
+			Modify:  func(txn *types.Transaction) { txn.Note = []byte("hello") },
+			WantErr: "transaction note must be the challenge message",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			challenge, err := underTest.Challenge(holder.Address.String())
+			assert.NoError(t, err)
+			var txn types.Transaction
+			txn.Type = types.PaymentTx
+			txn.Sender = holder.Address
+			txn.Receiver = holder.Address
+			txn.FirstValid = 1000
+			txn.LastValid = 999
+			txn.Note = []byte(challenge.Message)
+			if test.Modify != nil {
+				test.Modify(&txn)
+			}
+			_, signed, err := crypto.SignTransaction(holder.PrivateKey, txn)
+			assert.NoError(t, err)
+
+			_, claims, err := underTest.VerifyTransaction(context.Background(), challenge.Nonce, signed)
+
+			if test.WantErr != "" {
+				assert.EqualError(t, err, test.WantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Best Frens", "Mostly Frens"}, claims.Collections)
+		})
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	claims := gate.Claims{Address: holder.Address.String(), Collections: []string{"Mostly Frens"}, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+	token, err := gate.IssueToken(claims, issuer.PrivateKey)
+	assert.NoError(t, err)
+
+	verified, err := gate.VerifyToken(token, issuer.PublicKey, now)
+	assert.NoError(t, err)
+	assert.True(t, verified.Holds("Mostly Frens"))
+	assert.False(t, verified.Holds("Best Frens"))
+
+	_, err = gate.VerifyToken(token, issuer.PublicKey, now.Add(time.Hour))
+	assert.ErrorIs(t, err, gate.ErrTokenExpired)
+	_, err = gate.VerifyToken(token, visitor.PublicKey, now)
+	assert.ErrorIs(t, err, gate.ErrInvalidToken)
+	_, err = gate.VerifyToken("x"+token, issuer.PublicKey, now)
+	assert.ErrorIs(t, err, gate.ErrInvalidToken)
+}
@@ -0,0 +1,69 @@
+package gate
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims are the facts stated by a token.
+type Claims struct {
+	Address     string
+	Collections []string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// Holds reports whether the claims include the collection.
+func (c Claims) Holds(collectionName string) bool {
+	for _, name := range c.Collections {
+		if name == collectionName {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueToken encodes the claims as base64url JSON, a dot, and the base64url ed25519 signature of the encoded claims.
+func IssueToken(claims Claims, key ed25519.PrivateKey) (string, error) {
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(encodedClaims)
+	signature := ed25519.Sign(key, []byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyToken checks a token was issued with the key of publicKey and hasn't expired at now.
+func VerifyToken(token string, publicKey ed25519.PublicKey, now time.Time) (Claims, error) {
+	payload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return Claims{}, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !ed25519.Verify(publicKey, []byte(payload), signature) {
+		return Claims{}, ErrInvalidToken
+	}
+	encodedClaims, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(encodedClaims, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if !now.Before(claims.ExpiresAt) {
+		return Claims{}, ErrTokenExpired
+	}
+	return claims, nil
+}
@@ -0,0 +1,50 @@
+package holders
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// collectionDefinition is the part of a collection that decides its holdings.
+type collectionDefinition struct {
+	Name                    string
+	Addresses               []string
+	AssetIDs                []uint64
+	UnitNamePrefixes        []string
+	ExcludedAssets          []uint64
+	AssetIndexGreaterThan   uint64
+	ExcludedHolderAddresses []string
+	IncludeNameContains     []string
+	ExcludeNameContains     []string
+	MinimumBalance          float64
+	Filters                 []string
+}
+
+// HashCollections returns a SHA-256 of the collection definitions so that results produced
+// with different collection configs can be told apart. Custom filters are hashed by name
+// and asset sources are not hashed.
+func HashCollections(collections []Collection) string {
+	definitions := make([]collectionDefinition, len(collections))
+	for i, c := range collections {
+		definitions[i] = collectionDefinition{
+			Name:                    c.Name,
+			Addresses:               c.Addresses,
+			AssetIDs:                c.AssetIDs,
+			UnitNamePrefixes:        c.UnitNamePrefixes,
+			ExcludedAssets:          c.ExcludedAssets,
+			AssetIndexGreaterThan:   c.AssetIndexGreaterThan,
+			ExcludedHolderAddresses: c.ExcludedHolderAddresses,
+			IncludeNameContains:     c.IncludeNameContains,
+			ExcludeNameContains:     c.ExcludeNameContains,
+			MinimumBalance:          c.MinimumBalance,
+		}
+		for _, filter := range c.Filters {
+			definitions[i].Filters = append(definitions[i].Filters, filter.Name())
+		}
+	}
+
+	data, _ := json.Marshal(definitions)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// Cooldown keeps the winners of recent draws out of the next raffle, or reduces their chances.
+// A draw is recent when it is one of the last Draws draws, or was drawn less than Duration ago.
+type Cooldown struct {
+	Draws    int
+	Duration time.Duration
+	// Divisor reduces the chances of recent winners instead of excluding them: every other holder's
+	// tickets are multiplied by Divisor, so recent winners are Divisor times less likely to win.
+	// Zero excludes recent winners.
+	Divisor uint64
+}
+
+// RecentWinners returns the addresses that won a draw that is recent at now.
+func (l *Ledger) RecentWinners(cooldown Cooldown, now time.Time) map[string]bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	winners := make(map[string]bool)
+	for i, draw := range l.draws {
+		recentByCount := i >= len(l.draws)-cooldown.Draws
+		recentByTime := now.Sub(draw.Time) < cooldown.Duration
+		if !recentByCount && !recentByTime {
+			continue
+		}
+		for _, winner := range draw.Winners {
+			winners[winner.Address] = true
+		}
+	}
+	return winners
+}
+
+// ApplyCooldown returns the config and collections for a raffle of holdingsByCollection at now
+// with the cooldown applied to recent winners.
+func (l *Ledger) ApplyCooldown(cooldown Cooldown, now time.Time, config holders.RaffleConfig, weightedCollections []holders.WeightedCollection, holdingsByCollection map[string][]holders.AssetHolding) (holders.RaffleConfig, []holders.WeightedCollection, error) {
+	recentWinners := l.RecentWinners(cooldown, now)
+	if len(recentWinners) == 0 {
+		return config, weightedCollections, nil
+	}
+
+	if cooldown.Divisor == 0 {
+		var addresses []string
+		for address := range recentWinners {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		config.ExcludedWinnerWallets = append(append([]string{}, config.ExcludedWinnerWallets...), addresses...)
+		return config, weightedCollections, nil
+	}
+
+	weighted := make([]holders.WeightedCollection, len(weightedCollections))
+	for i, weightedCollection := range weightedCollections {
+		amountWeights := weightedCollection.AmountWeights
+		if amountWeights == nil {
+			amountWeights = holders.RawAmount
+		}
+		for _, holding := range holdingsByCollection[weightedCollection.Collection.Name] {
+			if recentWinners[holding.Address] {
+				continue
+			}
+			if hi, _ := bits.Mul64(amountWeights.AmountWeight(holding), cooldown.Divisor); hi != 0 {
+				return holders.RaffleConfig{}, nil, fmt.Errorf("%s: weight of asset %d held by %s overflows uint64 with the cooldown divisor",
+					weightedCollection.Collection.Name, holding.AssetID, holding.Address)
+			}
+		}
+		weightedCollection.AmountWeights = holders.AmountWeightFunc(func(holding holders.AssetHolding) uint64 {
+			if recentWinners[holding.Address] {
+				return amountWeights.AmountWeight(holding)
+			}
+			return amountWeights.AmountWeight(holding) * cooldown.Divisor
+		})
+		weighted[i] = weightedCollection
+	}
+	return config, weighted, nil
+}
@@ -0,0 +1,136 @@
+package history_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/history"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	now         = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	collections = []holders.WeightedCollection{{Collection: holders.Collection{Name: "Mostly Frens"}, Weight: 2}}
+	holdings    = map[string][]holders.AssetHolding{"Mostly Frens": {
+		{Address: "A", AssetID: 1, Amount: 1},
+		{Address: "B", AssetID: 2, Amount: 1},
+		{Address: "C", AssetID: 3, Amount: 1},
+	}}
+)
+
+func recordDraws(t *testing.T, ledger *history.Ledger) {
+	for i, winner := range []string{"A", "B", "C"} {
+		draw, err := history.NewDraw(winner, holdings, 100, collections, holders.RaffleConfig{RandSeed: "seed", NumberOfWinners: 1}, []holders.AssetHolding{{Address: winner}})
+		assert.NoError(t, err)
+		// a week apart, with the draw won by C yesterday
+		draw.Time = now.Add(-time.Duration(2-i)*7*24*time.Hour - 24*time.Hour)
+		assert.NoError(t, ledger.Record(draw))
+	}
+}
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raffles.jsonl")
+	ledger, err := history.OpenLedger(path)
+	assert.NoError(t, err)
+	recordDraws(t, ledger)
+
+	assert.EqualError(t, ledger.Record(history.Draw{ID: "A"}), `draw "A" is already recorded`)
+	assert.True(t, ledger.Recorded("A"))
+	assert.False(t, ledger.Recorded("D"))
+	assert.NoError(t, ledger.Close())
+
+	reopened, err := history.OpenLedger(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	draws := reopened.Draws()
+	assert.Len(t, draws, 3)
+	assert.Equal(t, "seed", draws[0].Seed)
+	assert.Equal(t, map[string]uint64{"Mostly Frens": 2}, draws[0].CollectionWeights)
+	assert.Equal(t, uint64(100), draws[0].Round)
+	assert.NotEmpty(t, draws[0].HoldingsHash)
+	assert.True(t, reopened.HasWon("B"))
+	assert.False(t, reopened.HasWon("D"))
+	assert.Equal(t, []history.Draw{draws[1]}, reopened.Wins("B"))
+}
+
+func TestRecentWinners(t *testing.T) {
+	ledger, err := history.OpenLedger(filepath.Join(t.TempDir(), "raffles.jsonl"))
+	assert.NoError(t, err)
+	defer ledger.Close()
+	recordDraws(t, ledger)
+
+	tests := map[string]struct {
+		Cooldown history.Cooldown
+		Want     map[string]bool
+	}{
+		"no cooldown": {
+			Want: map[string]bool{},
+		},
+		"last two draws": {
+			Cooldown: history.Cooldown{Draws: 2},
+			Want:     map[string]bool{"B": true, "C": true},
+		},
+		"last ten days": {
+			Cooldown: history.Cooldown{Duration: 10 * 24 * time.Hour},
+			Want:     map[string]bool{"B": true, "C": true},
+		},
+		"last draw or two days": {
+			Cooldown: history.Cooldown{Draws: 1, Duration: 2 * 24 * time.Hour},
+			Want:     map[string]bool{"C": true},
+		},
+		"more draws than recorded": {
+			Cooldown: history.Cooldown{Draws: 5},
+			Want:     map[string]bool{"A": true, "B": true, "C": true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Want, ledger.RecentWinners(test.Cooldown, now))
+		})
+	}
+}
+
+func TestApplyCooldown(t *testing.T) {
+	ledger, err := history.OpenLedger(filepath.Join(t.TempDir(), "raffles.jsonl"))
+	assert.NoError(t, err)
+	defer ledger.Close()
+	recordDraws(t, ledger)
+	config := holders.RaffleConfig{NumberOfWinners: 1, ExcludedWinnerWallets: []string{"CREATOR"}}
+
+	excludedConfig, _, err := ledger.ApplyCooldown(history.Cooldown{Draws: 2}, now, config, collections, holdings)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CREATOR", "B", "C"}, excludedConfig.ExcludedWinnerWallets)
+	winners, err := holders.RunWeightedRaffle(holdings, collections, excludedConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "A", winners[0].Address)
+
+	downWeightedConfig, downWeighted, err := ledger.ApplyCooldown(history.Cooldown{Draws: 2, Divisor: 4}, now, config, collections, holdings)
+	assert.NoError(t, err)
+	assert.Equal(t, config, downWeightedConfig)
+	holdingWeight := func(weightedCollection holders.WeightedCollection, holding holders.AssetHolding) uint64 {
+		weight, err := weightedCollection.HoldingWeight(holding)
+		assert.NoError(t, err)
+		return weight
+	}
+	assert.Equal(t, uint64(8), holdingWeight(downWeighted[0], holdings["Mostly Frens"][0]))
+	assert.Equal(t, uint64(2), holdingWeight(downWeighted[0], holdings["Mostly Frens"][1]))
+	assert.Equal(t, uint64(2), holdingWeight(collections[0], holdings["Mostly Frens"][0]))
+}
+
+func TestApplyCooldownOverflow(t *testing.T) {
+	ledger, err := history.OpenLedger(filepath.Join(t.TempDir(), "raffles.jsonl"))
+	assert.NoError(t, err)
+	defer ledger.Close()
+	recordDraws(t, ledger)
+	large := map[string][]holders.AssetHolding{"Mostly Frens": {{Address: "A", AssetID: 1, Amount: 1 << 62}}}
+
+	_, _, err = ledger.ApplyCooldown(history.Cooldown{Draws: 2, Divisor: 4}, now, holders.RaffleConfig{NumberOfWinners: 1}, collections, large)
+	assert.EqualError(t, err, "Mostly Frens: weight of asset 1 held by A overflows uint64 with the cooldown divisor")
+
+	// recent winners keep their weight, so theirs can't overflow
+	_, _, err = ledger.ApplyCooldown(history.Cooldown{Draws: 3, Divisor: 4}, now, holders.RaffleConfig{NumberOfWinners: 1}, collections, large)
+	assert.NoError(t, err)
+}
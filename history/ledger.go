@@ -0,0 +1,169 @@
+// Package history records raffle draws so later raffles can keep recent winners out, or reduce their chances.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/snapshot"
+	"os"
+	"sync"
+	"time"
+)
+
+// Draw records a raffle: how it was configured, what it was drawn from and who won.
+type Draw struct {
+	ID                    string
+	Time                  time.Time
+	Seed                  string
+	NumberOfWinners       int
+	ExcludedWinnerWallets []string
+	// CollectionWeights are the weights of the collections in the raffle, by name.
+	CollectionWeights map[string]uint64
+	// SetBonuses are the multipliers of the set bonuses in the raffle, by set name.
+	SetBonuses map[string]uint64 `json:",omitempty"`
+	// HoldingsHash is snapshot.HashHoldings of the holdings the winners were drawn from.
+	HoldingsHash string
+	Round        uint64 `json:",omitempty"`
+	// Cooldown is the cooldown applied to the winners of earlier draws, if any.
+	Cooldown *Cooldown `json:",omitempty"`
+	Winners  []holders.AssetHolding
+}
+
+// NewDraw describes a raffle run with RunWeightedRaffle. round is the round of the holdings, if known.
+func NewDraw(id string, holdingsByCollection map[string][]holders.AssetHolding, round uint64, weightedCollections []holders.WeightedCollection, config holders.RaffleConfig, winners []holders.AssetHolding) (Draw, error) {
+	hash, err := snapshot.HashHoldings(holdingsByCollection)
+	if err != nil {
+		return Draw{}, err
+	}
+	draw := Draw{
+		ID:                    id,
+		Time:                  time.Now().UTC(),
+		Seed:                  config.RandSeed,
+		NumberOfWinners:       config.NumberOfWinners,
+		ExcludedWinnerWallets: config.ExcludedWinnerWallets,
+		CollectionWeights:     make(map[string]uint64, len(weightedCollections)),
+		HoldingsHash:          hash,
+		Round:                 round,
+		Winners:               winners,
+	}
+	for _, weightedCollection := range weightedCollections {
+		draw.CollectionWeights[weightedCollection.Collection.Name] = weightedCollection.Weight
+	}
+	for _, setBonus := range config.SetBonuses {
+		if draw.SetBonuses == nil {
+			draw.SetBonuses = make(map[string]uint64)
+		}
+		draw.SetBonuses[setBonus.Set.Name] = setBonus.Multiplier
+	}
+	return draw, nil
+}
+
+// Ledger is an append-only file of JSON lines with a draw on each line, in the order they were recorded.
+type Ledger struct {
+	mutex sync.Mutex
+	file  *os.File
+	draws []Draw
+	ids   map[string]bool
+}
+
+// OpenLedger opens the ledger at path, creating it if needed, and reads its draws.
+func OpenLedger(path string) (*Ledger, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &Ledger{file: file, ids: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var draw Draw
+		if err := json.Unmarshal(scanner.Bytes(), &draw); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		ledger.draws = append(ledger.draws, draw)
+		ledger.ids[draw.ID] = true
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return ledger, nil
+}
+
+func (l *Ledger) Close() error {
+	return l.file.Close()
+}
+
+// Record appends a draw and syncs it to disk. Draw IDs must be unique, so a raffle can't be recorded twice.
+func (l *Ledger) Record(draw Draw) error {
+	if draw.ID == "" {
+		return errors.New("draw has no ID")
+	}
+	data, err := json.Marshal(draw)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.ids[draw.ID] {
+		return fmt.Errorf("draw %q is already recorded", draw.ID)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.draws = append(l.draws, draw)
+	l.ids[draw.ID] = true
+	return nil
+}
+
+// Recorded reports whether a draw with the ID has been recorded, so a raffle can be checked before it is drawn.
+func (l *Ledger) Recorded(id string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.ids[id]
+}
+
+// Draws returns every draw, oldest first.
+func (l *Ledger) Draws() []Draw {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]Draw{}, l.draws...)
+}
+
+// Wins returns the draws won by address, oldest first.
+func (l *Ledger) Wins(address string) []Draw {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var wins []Draw
+	for _, draw := range l.draws {
+		if draw.HasWinner(address) {
+			wins = append(wins, draw)
+		}
+	}
+	return wins
+}
+
+// HasWon reports whether address has won any recorded draw.
+func (l *Ledger) HasWon(address string) bool {
+	return len(l.Wins(address)) > 0
+}
+
+func (d Draw) HasWinner(address string) bool {
+	for _, winner := range d.Winners {
+		if winner.Address == address {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,8 @@ package holders
 
 import (
 	"context"
+	"errors"
+	"math"
 	"sync"
 )
 
@@ -9,6 +11,9 @@ type Asset struct {
 	Name     string
 	UnitName string
 	AssetID  uint64
+	URL      string
+	Total    uint64
+	Decimals uint64
 }
 
 type AssetHolding struct {
@@ -17,17 +22,53 @@ type AssetHolding struct {
 	Address  string
 	Amount   uint64
 	AssetID  uint64
+	Decimals uint64
+}
+
+func (h AssetHolding) Asset() Asset {
+	return Asset{
+		Name:     h.Name,
+		UnitName: h.UnitName,
+		AssetID:  h.AssetID,
+		Decimals: h.Decimals,
+	}
+}
+
+// DecimalAmount is the amount held in whole units of the asset, e.g. 1.5 tokens for 1500000 base units with 6 decimals.
+func (h AssetHolding) DecimalAmount() float64 {
+	return float64(h.Amount) / math.Pow10(int(h.Decimals))
 }
 
 type Collection struct {
-	Name                    string
-	Addresses               []string
+	Name      string
+	Addresses []string
+	// AssetIDs and AssetSource list assets directly, in addition to those created by Addresses.
+	AssetIDs                []uint64
+	AssetSource             AssetSource
 	UnitNamePrefixes        []string
 	ExcludedAssets          []uint64
 	AssetIndexGreaterThan   uint64
 	ExcludedHolderAddresses []string
 	IncludeNameContains     []string
 	ExcludeNameContains     []string
+	// MinimumBalance excludes holders with less than this many whole units of an asset.
+	MinimumBalance float64
+	// Filters are applied after the filters translated from the fields above. See AssetFilters.
+	Filters []AssetFilter
+}
+
+// IncludesHolding reports whether a holding counts towards the collection, applying
+// ExcludedHolderAddresses and MinimumBalance.
+func (c Collection) IncludesHolding(holding AssetHolding) bool {
+	if holding.Amount == 0 || holding.DecimalAmount() < c.MinimumBalance {
+		return false
+	}
+	for _, excludedHolderAddress := range c.ExcludedHolderAddresses {
+		if excludedHolderAddress == holding.Address {
+			return false
+		}
+	}
+	return true
 }
 
 type CollectionClient interface {
@@ -36,6 +77,33 @@ type CollectionClient interface {
 	IsAssetOwned(ctx context.Context, asset Asset) (bool, error)
 }
 
+// CollectionExplainer reports why each asset created by a collection's addresses was included or excluded.
+type CollectionExplainer interface {
+	ExplainCollection(ctx context.Context, collection Collection) ([]AssetExplanation, error)
+}
+
+type FilterVerdict struct {
+	Filter string `json:"filter"`
+	Passed bool   `json:"passed"`
+}
+
+// AssetExplanation is the verdict of every filter of a collection for one created asset.
+type AssetExplanation struct {
+	Asset    Asset           `json:"asset"`
+	Creator  string          `json:"creator"`
+	Filters  []FilterVerdict `json:"filters"`
+	Included bool            `json:"included"`
+}
+
+// ExplainCollection explains the collection filters if the client supports it.
+func ExplainCollection(ctx context.Context, client CollectionClient, collection Collection) ([]AssetExplanation, error) {
+	explainer, ok := client.(CollectionExplainer)
+	if !ok {
+		return nil, errors.New("collection client does not support explaining collections")
+	}
+	return explainer.ExplainCollection(ctx, collection)
+}
+
 func GetAssetHoldingsByCollection(ctx context.Context, client CollectionClient, collections []Collection, concurrency int) (map[string][]AssetHolding, error) {
 	result := make(map[string][]AssetHolding)
 	resultMutex := &sync.Mutex{}
@@ -0,0 +1,137 @@
+package holders
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// AccountAsset is an asset balance of a single account.
+type AccountAsset struct {
+	AssetID uint64
+	Amount  uint64
+}
+
+type AccountAssetsClient interface {
+	GetAccountAssets(ctx context.Context, address string) ([]AccountAsset, error)
+}
+
+// AssetOptInClient checks whether an account can receive an asset.
+type AssetOptInClient interface {
+	IsOptedIn(ctx context.Context, address string, assetID uint64) (bool, error)
+}
+
+// AuthAddressClient returns the address whose key signs for an account. It is the account itself unless it has been rekeyed.
+type AuthAddressClient interface {
+	AuthAddress(ctx context.Context, address string) (string, error)
+}
+
+const (
+	// DefaultLookupTTL is how long a HoldingsLookup caches the assets of a collection when its TTL is zero.
+	DefaultLookupTTL = 5 * time.Minute
+	// LookupTTLForever caches the assets of a collection until Invalidate is called.
+	LookupTTLForever = time.Duration(math.MaxInt64)
+)
+
+// HoldingsLookup answers which collections an address holds. The assets of each collection are
+// cached by collection name, so a lookup only needs the account's own assets.
+type HoldingsLookup struct {
+	// TTL is how long the assets of a collection are cached, DefaultLookupTTL when zero. A negative TTL
+	// fetches them on every lookup, for collection clients that cache them already.
+	TTL time.Duration
+
+	collectionClient CollectionClient
+	accountClient    AccountAssetsClient
+
+	mutex              sync.Mutex
+	assetsByCollection map[string]cachedAssets
+}
+
+type cachedAssets struct {
+	assets    map[uint64]Asset
+	fetchedAt time.Time
+}
+
+func NewHoldingsLookup(collectionClient CollectionClient, accountClient AccountAssetsClient) *HoldingsLookup {
+	return &HoldingsLookup{
+		collectionClient:   collectionClient,
+		accountClient:      accountClient,
+		assetsByCollection: make(map[string]cachedAssets),
+	}
+}
+
+// GetHoldingsByAddress returns the holdings of the address grouped by collection name.
+// Collections the address does not hold are omitted.
+func (l *HoldingsLookup) GetHoldingsByAddress(ctx context.Context, address string, collections []Collection) (map[string][]AssetHolding, error) {
+	accountAssets, err := l.accountClient.GetAccountAssets(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]AssetHolding)
+	for _, collection := range collections {
+		assets, err := l.collectionAssets(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, accountAsset := range accountAssets {
+			asset, found := assets[accountAsset.AssetID]
+			if !found || accountAsset.Amount == 0 {
+				continue
+			}
+			holding := AssetHolding{
+				Name:     asset.Name,
+				UnitName: asset.UnitName,
+				Address:  address,
+				Amount:   accountAsset.Amount,
+				AssetID:  asset.AssetID,
+				Decimals: asset.Decimals,
+			}
+			if collection.IncludesHolding(holding) {
+				result[collection.Name] = append(result[collection.Name], holding)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Invalidate drops the cached assets of a collection so they are fetched again on the next lookup.
+func (l *HoldingsLookup) Invalidate(collectionName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.assetsByCollection, collectionName)
+}
+
+func (l *HoldingsLookup) collectionAssets(ctx context.Context, collection Collection) (map[uint64]Asset, error) {
+	l.mutex.Lock()
+	cached, found := l.assetsByCollection[collection.Name]
+	l.mutex.Unlock()
+	ttl := l.TTL
+	if ttl == 0 {
+		ttl = DefaultLookupTTL
+	}
+	if found && time.Since(cached.fetchedAt) < ttl {
+		return cached.assets, nil
+	}
+
+	fetchedAt := time.Now()
+	fetched, err := l.collectionClient.GetAssetsByCollection(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	assets := make(map[uint64]Asset, len(fetched))
+	for _, asset := range fetched {
+		assets[asset.AssetID] = asset
+	}
+
+	if ttl > 0 {
+		l.mutex.Lock()
+		l.assetsByCollection[collection.Name] = cachedAssets{assets: assets, fetchedAt: fetchedAt}
+		l.mutex.Unlock()
+	}
+
+	return assets, nil
+}
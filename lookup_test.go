@@ -0,0 +1,69 @@
+package holders_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/testdata"
+	"testing"
+	"time"
+)
+
+func TestGetHoldingsByAddress(t *testing.T) {
+	client := &testdata.FakeCollectionClient{
+		Assets: map[string][]holders.Asset{
+			"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}, {AssetID: 2, UnitName: "MFER002"}},
+			"Best Frens":   {{AssetID: 3, UnitName: "BFER001"}},
+			"Fren Token":   {{AssetID: 4, UnitName: "FREN", Decimals: 6}},
+		},
+		AccountAssets: map[string][]holders.AccountAsset{
+			testdata.TestAccount2Address: {{AssetID: 1, Amount: 1}, {AssetID: 2, Amount: 1}, {AssetID: 4, Amount: 500000}, {AssetID: 99, Amount: 1}},
+		},
+	}
+	collections := []holders.Collection{
+		{Name: "Mostly Frens"},
+		{Name: "Best Frens"},
+		{Name: "Fren Token", MinimumBalance: 1},
+	}
+	underTest := holders.NewHoldingsLookup(client, client)
+
+	holdings, err := underTest.GetHoldingsByAddress(context.Background(), testdata.TestAccount2Address, collections)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: testdata.TestAccount2Address, Amount: 1, AssetID: 1, UnitName: "MFER001"},
+			{Address: testdata.TestAccount2Address, Amount: 1, AssetID: 2, UnitName: "MFER002"},
+		},
+	}, holdings)
+
+	_, err = underTest.GetHoldingsByAddress(context.Background(), testdata.TestAccount1Address, collections)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, client.CallCount("GetAssetsByCollection"))
+	assert.Equal(t, 2, client.CallCount("GetAccountAssets"))
+
+	underTest.Invalidate("Best Frens")
+	_, err = underTest.GetHoldingsByAddress(context.Background(), testdata.TestAccount1Address, collections)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, client.CallCount("GetAssetsByCollection"))
+}
+
+func TestGetHoldingsByAddressTTL(t *testing.T) {
+	collections := []holders.Collection{{Name: "Mostly Frens"}}
+	lookupTwice := func(ttl time.Duration) int {
+		client := &testdata.FakeCollectionClient{}
+		underTest := holders.NewHoldingsLookup(client, client)
+		underTest.TTL = ttl
+		for i := 0; i < 2; i++ {
+			_, err := underTest.GetHoldingsByAddress(context.Background(), testdata.TestAccount1Address, collections)
+			assert.NoError(t, err)
+			time.Sleep(time.Millisecond)
+		}
+		return client.CallCount("GetAssetsByCollection")
+	}
+
+	assert.Equal(t, 1, lookupTwice(0))
+	assert.Equal(t, 1, lookupTwice(holders.LookupTTLForever))
+	assert.Equal(t, 1, lookupTwice(time.Hour))
+	assert.Equal(t, 2, lookupTwice(time.Millisecond))
+	assert.Equal(t, 2, lookupTwice(-1))
+}
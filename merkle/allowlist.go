@@ -0,0 +1,135 @@
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/snapshot"
+	"io"
+	"os"
+)
+
+// Allowlist is the exported tree: the root to store on-chain and a proof for every address.
+type Allowlist struct {
+	Scheme string
+	Root   Hash
+	// Round is the round of the snapshot the allowlist was made from, if any.
+	Round  uint64
+	Proofs []Proof
+}
+
+// Proof returns the proof of address, if it is on the allowlist.
+func (a Allowlist) Proof(address string) (Proof, bool) {
+	for _, proof := range a.Proofs {
+		if proof.Address == address {
+			return proof, true
+		}
+	}
+	return Proof{}, false
+}
+
+// Allowlist exports the root and the proofs of every leaf, ordered by address.
+func (t *Tree) Allowlist(round uint64) Allowlist {
+	allowlist := Allowlist{Scheme: Scheme, Root: t.Root(), Round: round}
+	for _, leaf := range t.leaves {
+		proof, _ := t.Proof(leaf.Address)
+		allowlist.Proofs = append(allowlist.Proofs, proof)
+	}
+	return allowlist
+}
+
+// LeavesFromHoldings allocates each address the sum of the weights of its holdings,
+// weighed like raffle tickets. Holdings of collections not in weightedCollections are ignored.
+// Excluded addresses, such as creator and escrow wallets, and addresses with no weight are left out.
+func LeavesFromHoldings(holdingsByCollection map[string][]holders.AssetHolding, weightedCollections []holders.WeightedCollection, excludedAddresses []string) ([]Leaf, error) {
+	excluded := make(map[string]bool, len(excludedAddresses))
+	for _, address := range excludedAddresses {
+		excluded[address] = true
+	}
+
+	allocations := make(map[string]uint64)
+	var addresses []string
+	for _, weightedCollection := range weightedCollections {
+		for _, holding := range holdingsByCollection[weightedCollection.Collection.Name] {
+			if excluded[holding.Address] {
+				continue
+			}
+			weight, err := weightedCollection.HoldingWeight(holding)
+			if err != nil {
+				return nil, err
+			}
+			if weight == 0 {
+				continue
+			}
+			if allocations[holding.Address] > ^uint64(0)-weight {
+				return nil, fmt.Errorf("allocation of %s overflows uint64", holding.Address)
+			}
+			if _, found := allocations[holding.Address]; !found {
+				addresses = append(addresses, holding.Address)
+			}
+			allocations[holding.Address] += weight
+		}
+	}
+
+	leaves := make([]Leaf, 0, len(addresses))
+	for _, address := range addresses {
+		leaves = append(leaves, Leaf{Address: address, Allocation: allocations[address]})
+	}
+	return leaves, nil
+}
+
+// FromSnapshot builds the allowlist of a snapshot, allocating as LeavesFromHoldings does.
+func FromSnapshot(snap snapshot.Snapshot, weightedCollections []holders.WeightedCollection, excludedAddresses []string) (Allowlist, error) {
+	leaves, err := LeavesFromHoldings(snap.Holdings, weightedCollections, excludedAddresses)
+	if err != nil {
+		return Allowlist{}, err
+	}
+	tree, err := New(leaves)
+	if err != nil {
+		return Allowlist{}, err
+	}
+	return tree.Allowlist(snap.Round), nil
+}
+
+func Write(w io.Writer, allowlist Allowlist) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(allowlist)
+}
+
+func Read(r io.Reader) (Allowlist, error) {
+	var allowlist Allowlist
+	if err := json.NewDecoder(r).Decode(&allowlist); err != nil {
+		return Allowlist{}, err
+	}
+	if allowlist.Scheme != Scheme {
+		return Allowlist{}, fmt.Errorf("unsupported hash scheme %q", allowlist.Scheme)
+	}
+	return allowlist, nil
+}
+
+func Save(path string, allowlist Allowlist) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, allowlist); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func Load(path string) (Allowlist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Allowlist{}, err
+	}
+	defer file.Close()
+
+	allowlist, err := Read(file)
+	if err != nil {
+		return Allowlist{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return allowlist, nil
+}
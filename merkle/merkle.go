@@ -0,0 +1,182 @@
+// Package merkle builds Merkle allowlists of (address, allocation) leaves, e.g. for claim contracts.
+//
+// The hash scheme uses SHA-512/256, which the AVM provides as the sha512_256 opcode:
+//
+//	leaf = SHA-512/256(0x00 || public key of the address (32 bytes) || allocation (uint64, big-endian))
+//	node = SHA-512/256(0x01 || min(left, right) || max(left, right))
+//
+// The 0x00 and 0x01 prefixes keep a leaf from being passed off as a node. Children are sorted before
+// hashing, so a proof is just the list of sibling hashes from the leaf up, without left or right flags.
+// Leaves are ordered by address. A node without a sibling is promoted to the next level unchanged.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/types"
+	"sort"
+)
+
+// Scheme describes the hash scheme, recorded in exported allowlists.
+const Scheme = "sha512_256; leaf = H(0x00 || pubkey || be64(allocation)); node = H(0x01 || min(a, b) || max(a, b))"
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var ErrInvalidProof = errors.New("proof does not match the root")
+
+// Hash is a SHA-512/256 digest, encoded as hex in JSON.
+type Hash [32]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(h) {
+		return fmt.Errorf("hash must be %d bytes, got %d", len(h), len(decoded))
+	}
+	copy(h[:], decoded)
+	return nil
+}
+
+type Leaf struct {
+	Address    string
+	Allocation uint64
+}
+
+// Hash is the leaf hash of the scheme.
+func (l Leaf) Hash() (Hash, error) {
+	address, err := types.DecodeAddress(l.Address)
+	if err != nil {
+		return Hash{}, fmt.Errorf("invalid address %q: %w", l.Address, err)
+	}
+	data := make([]byte, 0, 1+len(address)+8)
+	data = append(data, leafPrefix)
+	data = append(data, address[:]...)
+	data = binary.BigEndian.AppendUint64(data, l.Allocation)
+	return sha512.Sum512_256(data), nil
+}
+
+// Proof shows that a leaf is in the tree with the given root.
+type Proof struct {
+	Address    string
+	Allocation uint64
+	// Siblings are the hashes to combine with, from the leaf up to the root.
+	Siblings []Hash
+}
+
+type Tree struct {
+	leaves []Leaf
+	// levels[0] are the leaf hashes and the last level is the root.
+	levels [][]Hash
+	index  map[string]int
+}
+
+// New builds the tree of leaves. Every address must be valid and appear once.
+func New(leaves []Leaf) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("no leaves")
+	}
+
+	sorted := append([]Leaf(nil), leaves...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Address < sorted[j].Address
+	})
+
+	tree := &Tree{leaves: sorted, index: make(map[string]int, len(sorted))}
+	level := make([]Hash, len(sorted))
+	for i, leaf := range sorted {
+		if _, found := tree.index[leaf.Address]; found {
+			return nil, fmt.Errorf("duplicate address %s", leaf.Address)
+		}
+		tree.index[leaf.Address] = i
+
+		hash, err := leaf.Hash()
+		if err != nil {
+			return nil, err
+		}
+		level[i] = hash
+	}
+
+	tree.levels = append(tree.levels, level)
+	for len(level) > 1 {
+		next := make([]Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree, nil
+}
+
+func (t *Tree) Root() Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+func (t *Tree) Leaves() []Leaf {
+	return t.leaves
+}
+
+// Proof returns the proof for address, if it is in the tree.
+func (t *Tree) Proof(address string) (Proof, bool) {
+	index, found := t.index[address]
+	if !found {
+		return Proof{}, false
+	}
+
+	proof := Proof{Address: address, Allocation: t.leaves[index].Allocation, Siblings: []Hash{}}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Siblings = append(proof.Siblings, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, true
+}
+
+// Verify checks that proof leads to root.
+func Verify(root Hash, proof Proof) error {
+	hash, err := Leaf{Address: proof.Address, Allocation: proof.Allocation}.Hash()
+	if err != nil {
+		return err
+	}
+	for _, sibling := range proof.Siblings {
+		hash = hashNode(hash, sibling)
+	}
+	if hash != root {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+func hashNode(a Hash, b Hash) Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	data := make([]byte, 0, 1+2*len(a))
+	data = append(data, nodePrefix)
+	data = append(data, a[:]...)
+	data = append(data, b[:]...)
+	return sha512.Sum512_256(data)
+}
@@ -0,0 +1,110 @@
+package merkle_test
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/merkle"
+	"github.com/yellowbackground/holders/snapshot"
+	"testing"
+)
+
+func testAddress(i byte) string {
+	var address types.Address
+	address[0] = i
+	return address.String()
+}
+
+func testLeaves(n int) []merkle.Leaf {
+	leaves := make([]merkle.Leaf, n)
+	for i := range leaves {
+		leaves[i] = merkle.Leaf{Address: testAddress(byte(i + 1)), Allocation: uint64(i+1) * 100}
+	}
+	return leaves
+}
+
+func TestProofs(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		tree, err := merkle.New(testLeaves(n))
+		assert.NoError(t, err)
+
+		for _, leaf := range testLeaves(n) {
+			proof, found := tree.Proof(leaf.Address)
+			assert.True(t, found)
+			assert.Equal(t, leaf.Allocation, proof.Allocation)
+			assert.NoError(t, merkle.Verify(tree.Root(), proof), "%d leaves, %s", n, leaf.Address)
+
+			proof.Allocation++
+			assert.ErrorIs(t, merkle.Verify(tree.Root(), proof), merkle.ErrInvalidProof)
+		}
+	}
+}
+
+func TestHashScheme(t *testing.T) {
+	leaves := testLeaves(2)
+	tree, err := merkle.New(leaves)
+	assert.NoError(t, err)
+
+	leafHash := func(leaf merkle.Leaf) [32]byte {
+		address, _ := types.DecodeAddress(leaf.Address)
+		data := append([]byte{0x00}, address[:]...)
+		data = append(data, 0, 0, 0, 0, 0, 0, 0, byte(leaf.Allocation))
+		return sha512.Sum512_256(data)
+	}
+	a, b := leafHash(leaves[0]), leafHash(leaves[1])
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	want := sha512.Sum512_256(append(append([]byte{0x01}, a[:]...), b[:]...))
+
+	assert.Equal(t, merkle.Hash(want), tree.Root())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := merkle.New(nil)
+	assert.EqualError(t, err, "no leaves")
+
+	_, err = merkle.New([]merkle.Leaf{{Address: testAddress(1)}, {Address: testAddress(1)}})
+	assert.EqualError(t, err, "duplicate address "+testAddress(1))
+
+	_, err = merkle.New([]merkle.Leaf{{Address: "nope"}})
+	assert.ErrorContains(t, err, `invalid address "nope"`)
+}
+
+func TestFromSnapshot(t *testing.T) {
+	snap := snapshot.Snapshot{Version: snapshot.Version, Round: 100, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: testAddress(1), Amount: 1, AssetID: 1},
+			{Address: testAddress(1), Amount: 1, AssetID: 2},
+			{Address: testAddress(2), Amount: 1, AssetID: 3},
+		},
+		"Best Frens": {{Address: testAddress(2), Amount: 1, AssetID: 4}, {Address: testAddress(4), Amount: 1, AssetID: 6}},
+		"Ignored":    {{Address: testAddress(3), Amount: 1, AssetID: 5}},
+	}}
+	weightedCollections := []holders.WeightedCollection{
+		{Collection: holders.Collection{Name: "Mostly Frens"}, Weight: 1},
+		{Collection: holders.Collection{Name: "Best Frens"}, Weight: 3},
+	}
+
+	allowlist, err := merkle.FromSnapshot(snap, weightedCollections, []string{testAddress(4)})
+	assert.NoError(t, err)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, merkle.Write(&buffer, allowlist))
+	read, err := merkle.Read(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, allowlist, read)
+
+	assert.Equal(t, uint64(100), read.Round)
+	assert.Len(t, read.Proofs, 2)
+	proof, found := read.Proof(testAddress(2))
+	assert.True(t, found)
+	assert.Equal(t, uint64(4), proof.Allocation)
+	assert.NoError(t, merkle.Verify(read.Root, proof))
+	_, found = read.Proof(testAddress(3))
+	assert.False(t, found)
+	_, found = read.Proof(testAddress(4))
+	assert.False(t, found)
+}
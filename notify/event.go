@@ -0,0 +1,103 @@
+// Package notify detects changes to collection holders, such as new holders and whales, and sends them to
+// webhooks, files or the terminal.
+package notify
+
+import (
+	"context"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/snapshot"
+)
+
+type EventType string
+
+const (
+	NewHolder    EventType = "new_holder"
+	HolderExited EventType = "holder_exited"
+	// WhaleThresholdCrossed is sent when a holder's balance of a collection reaches its whale threshold.
+	WhaleThresholdCrossed EventType = "whale_threshold_crossed"
+	// SoldOut is sent when the creator wallets of a collection no longer hold any of its assets.
+	SoldOut EventType = "sold_out"
+)
+
+// Event is a change to the holders of a collection at Round. Balance and Threshold are whole units and
+// are only set for WhaleThresholdCrossed. Address is not set for SoldOut.
+type Event struct {
+	Type       EventType
+	Round      uint64
+	Collection string
+	Address    string
+	Balance    float64
+	Threshold  float64
+}
+
+// EventSink receives events, in order.
+type EventSink interface {
+	Send(ctx context.Context, events []Event) error
+}
+
+type Options struct {
+	// WhaleThresholds are the balances, in whole units, at which a holder becomes a whale, by collection name.
+	WhaleThresholds map[string]float64
+	// Collections are used to find the creator wallets of each collection, which are never reported as holders.
+	// SoldOut is only detected for collections that don't exclude their creator wallets from holdings.
+	Collections []holders.Collection
+}
+
+// Detect returns the events between two snapshots by collection: new holders, exited holders and whales,
+// each ordered by address, and then sold out.
+func Detect(from snapshot.Snapshot, to snapshot.Snapshot, options Options) []Event {
+	var events []Event
+	for _, diff := range snapshot.Compare(from, to).Collections {
+		creators := options.creators(diff.Collection)
+		threshold := options.WhaleThresholds[diff.Collection]
+
+		for _, address := range diff.NewHolders {
+			if !creators[address] {
+				events = append(events, Event{Type: NewHolder, Round: to.Round, Collection: diff.Collection, Address: address})
+			}
+		}
+		for _, address := range diff.ExitedHolders {
+			if !creators[address] {
+				events = append(events, Event{Type: HolderExited, Round: to.Round, Collection: diff.Collection, Address: address})
+			}
+		}
+		for _, change := range diff.BalanceChanges {
+			if threshold > 0 && !creators[change.Address] && change.Before < threshold && change.After >= threshold {
+				events = append(events, Event{
+					Type:       WhaleThresholdCrossed,
+					Round:      to.Round,
+					Collection: diff.Collection,
+					Address:    change.Address,
+					Balance:    change.After,
+					Threshold:  threshold,
+				})
+			}
+		}
+		if heldBy(from.Holdings[diff.Collection], creators) > 0 && heldBy(to.Holdings[diff.Collection], creators) == 0 {
+			events = append(events, Event{Type: SoldOut, Round: to.Round, Collection: diff.Collection})
+		}
+	}
+	return events
+}
+
+func (o Options) creators(collectionName string) map[string]bool {
+	creators := make(map[string]bool)
+	for _, collection := range o.Collections {
+		if collection.Name == collectionName {
+			for _, address := range collection.Addresses {
+				creators[address] = true
+			}
+		}
+	}
+	return creators
+}
+
+func heldBy(holdings []holders.AssetHolding, addresses map[string]bool) float64 {
+	var total float64
+	for _, holding := range holdings {
+		if addresses[holding.Address] {
+			total += holding.DecimalAmount()
+		}
+	}
+	return total
+}
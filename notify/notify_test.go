@@ -0,0 +1,147 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/notify"
+	"github.com/yellowbackground/holders/snapshot"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var options = notify.Options{
+	WhaleThresholds: map[string]float64{"Mostly Frens": 3},
+	Collections:     []holders.Collection{{Name: "Mostly Frens", Addresses: []string{"CREATOR"}}},
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]struct {
+		From []holders.AssetHolding
+		To   []holders.AssetHolding
+		Want []notify.Event
+	}{
+		"new and exited holders": {
+			From: []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}},
+			To:   []holders.AssetHolding{{Address: "B", AssetID: 1, Amount: 1}},
+			Want: []notify.Event{
+				{Type: notify.NewHolder, Round: 20, Collection: "Mostly Frens", Address: "B"},
+				{Type: notify.HolderExited, Round: 20, Collection: "Mostly Frens", Address: "A"},
+			},
+		},
+		"grouped by type, then address": {
+			From: []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}, {Address: "C", AssetID: 2, Amount: 1}},
+			To:   []holders.AssetHolding{{Address: "D", AssetID: 1, Amount: 1}, {Address: "B", AssetID: 2, Amount: 1}},
+			Want: []notify.Event{
+				{Type: notify.NewHolder, Round: 20, Collection: "Mostly Frens", Address: "B"},
+				{Type: notify.NewHolder, Round: 20, Collection: "Mostly Frens", Address: "D"},
+				{Type: notify.HolderExited, Round: 20, Collection: "Mostly Frens", Address: "A"},
+				{Type: notify.HolderExited, Round: 20, Collection: "Mostly Frens", Address: "C"},
+			},
+		},
+		"whale threshold crossed": {
+			From: []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}, {Address: "A", AssetID: 2, Amount: 1}, {Address: "B", AssetID: 3, Amount: 4}},
+			To:   []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}, {Address: "A", AssetID: 2, Amount: 1}, {Address: "A", AssetID: 3, Amount: 1}, {Address: "B", AssetID: 3, Amount: 3}},
+			Want: []notify.Event{
+				{Type: notify.WhaleThresholdCrossed, Round: 20, Collection: "Mostly Frens", Address: "A", Balance: 3, Threshold: 3},
+			},
+		},
+		"sold out": {
+			From: []holders.AssetHolding{{Address: "CREATOR", AssetID: 1, Amount: 1}},
+			To:   []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}},
+			Want: []notify.Event{
+				{Type: notify.NewHolder, Round: 20, Collection: "Mostly Frens", Address: "A"},
+				{Type: notify.SoldOut, Round: 20, Collection: "Mostly Frens"},
+			},
+		},
+		"no changes": {
+			From: []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}},
+			To:   []holders.AssetHolding{{Address: "A", AssetID: 1, Amount: 1}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			from := snapshot.Snapshot{Round: 10, Holdings: map[string][]holders.AssetHolding{"Mostly Frens": test.From}}
+			to := snapshot.Snapshot{Round: 20, Holdings: map[string][]holders.AssetHolding{"Mostly Frens": test.To}}
+
+			assert.Equal(t, test.Want, notify.Detect(from, to, options))
+		})
+	}
+}
+
+var events = []notify.Event{{Type: notify.NewHolder, Round: 20, Collection: "Mostly Frens", Address: "B"}}
+
+func TestWebhookSink(t *testing.T) {
+	var attempts atomic.Int32
+	var received notify.WebhookPayload
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !notify.VerifySignature([]byte("secret"), r.Header.Get(notify.TimestampHeader), body, r.Header.Get(notify.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+	}))
+	defer receiver.Close()
+
+	underTest := notify.NewWebhookSink(receiver.URL, "secret")
+	underTest.Backoff = time.Millisecond
+
+	assert.NoError(t, underTest.Send(context.Background(), events))
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Equal(t, events, received.Events)
+}
+
+func TestWebhookSinkDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer receiver.Close()
+
+	underTest := notify.NewWebhookSink(receiver.URL, "wrong secret")
+	underTest.Backoff = time.Millisecond
+
+	err := underTest.Send(context.Background(), events)
+
+	assert.ErrorContains(t, err, "unexpected status 401 Unauthorized")
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestNDJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	underTest, err := notify.OpenNDJSONFile(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, underTest.Send(context.Background(), events))
+	assert.NoError(t, underTest.Send(context.Background(), events))
+	assert.NoError(t, underTest.Close())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	line := `{"Type":"new_holder","Round":20,"Collection":"Mostly Frens","Address":"B","Balance":0,"Threshold":0}` + "\n"
+	assert.Equal(t, line+line, string(content))
+}
+
+func TestTextSink(t *testing.T) {
+	var out bytes.Buffer
+	underTest := notify.NewTextSink(&out)
+
+	assert.NoError(t, underTest.Send(context.Background(), append(events, notify.Event{Type: notify.SoldOut, Round: 21, Collection: "Mostly Frens"})))
+
+	assert.Equal(t, "round 20 Mostly Frens: new holder B\nround 21 Mostly Frens: sold out of the creator wallets\n", out.String())
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"github.com/yellowbackground/holders/snapshot"
+	"github.com/yellowbackground/holders/tracker"
+)
+
+const relayBuffer = 1024
+
+// Relay sends the changes seen by a tracker to a sink.
+// Holdings are compared after every batch of tracker events, so the events are the same as Detect between rounds.
+type Relay struct {
+	tracker       *tracker.Tracker
+	sink          EventSink
+	options       Options
+	trackerEvents <-chan tracker.Event
+	unsubscribe   func()
+	previous      snapshot.Snapshot
+}
+
+// NewRelay subscribes to the tracker straight away, so create it before running the tracker to see every change.
+func NewRelay(t *tracker.Tracker, sink EventSink, options Options) *Relay {
+	trackerEvents, unsubscribe := t.Subscribe(relayBuffer)
+	return &Relay{
+		tracker:       t,
+		sink:          sink,
+		options:       options,
+		trackerEvents: trackerEvents,
+		unsubscribe:   unsubscribe,
+		previous:      snapshot.Snapshot{Round: t.Round(), Holdings: t.Holdings()},
+	}
+}
+
+// Run sends events until ctx is done or the sink fails, then unsubscribes from the tracker.
+func (r *Relay) Run(ctx context.Context) error {
+	defer r.unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.trackerEvents:
+		}
+		r.drain()
+
+		current := snapshot.Snapshot{Round: r.tracker.Round(), Holdings: r.tracker.Holdings()}
+		if events := Detect(r.previous, current, r.options); len(events) > 0 {
+			if err := r.sink.Send(ctx, events); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+		r.previous = current
+	}
+}
+
+func (r *Relay) drain() {
+	for {
+		select {
+		case <-r.trackerEvents:
+		default:
+			return
+		}
+	}
+}
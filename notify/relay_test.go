@@ -0,0 +1,61 @@
+package notify_test
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/notify"
+	"github.com/yellowbackground/holders/testdata"
+	"github.com/yellowbackground/holders/tracker"
+	"testing"
+)
+
+type channelSink chan []notify.Event
+
+func (c channelSink) Send(_ context.Context, events []notify.Event) error {
+	c <- events
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	var creator, buyer types.Address
+	creator[0], buyer[0] = 1, 2
+
+	var sale types.Block
+	sale.Round = 101
+	sale.Payset = []types.SignedTxnInBlock{{}}
+	sale.Payset[0].Txn.Type = types.AssetTransferTx
+	sale.Payset[0].Txn.Sender = creator
+	sale.Payset[0].Txn.XferAsset = 1
+	sale.Payset[0].Txn.AssetReceiver = buyer
+	sale.Payset[0].Txn.AssetAmount = 1
+
+	client := &testdata.FakeCollectionClient{
+		Assets:         map[string][]holders.Asset{"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}}},
+		AssetBalances:  map[uint64]map[string]uint64{1: {creator.String(): 1}},
+		BalancesRounds: map[uint64]uint64{1: 100},
+	}
+	collections := []holders.Collection{{Name: "Mostly Frens", Addresses: []string{creator.String()}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	followed, err := tracker.New(ctx, &testdata.FakeBlockClient{Blocks: map[uint64]types.Block{101: sale}}, client, client, collections, tracker.Options{})
+	assert.NoError(t, err)
+
+	sink := make(channelSink)
+	underTest := notify.NewRelay(followed, sink, notify.Options{Collections: collections})
+	relayDone := make(chan error)
+	go func() {
+		relayDone <- underTest.Run(ctx)
+	}()
+	go func() {
+		_ = followed.Run(ctx)
+	}()
+
+	assert.Equal(t, []notify.Event{
+		{Type: notify.NewHolder, Round: 101, Collection: "Mostly Frens", Address: buyer.String()},
+		{Type: notify.SoldOut, Round: 101, Collection: "Mostly Frens"},
+	}, <-sink)
+	cancel()
+	assert.NoError(t, <-relayDone)
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// NDJSONSink writes each event as a line of JSON.
+type NDJSONSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{encoder: json.NewEncoder(w)}
+}
+
+// OpenNDJSONFile appends events to the file at path, creating it if needed.
+func OpenNDJSONFile(path string) (*NDJSONSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	sink := NewNDJSONSink(file)
+	sink.closer = file
+	return sink, nil
+}
+
+func (s *NDJSONSink) Send(_ context.Context, events []Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, event := range events {
+		if err := s.encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the file opened by OpenNDJSONFile.
+func (s *NDJSONSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+type textSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewTextSink writes a human readable line for each event.
+func NewTextSink(w io.Writer) EventSink {
+	return &textSink{w: w}
+}
+
+func NewStdoutSink() EventSink {
+	return NewTextSink(os.Stdout)
+}
+
+func (s *textSink) Send(_ context.Context, events []Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, event := range events {
+		if _, err := fmt.Fprintln(s.w, describe(event)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describe(event Event) string {
+	prefix := fmt.Sprintf("round %d %s:", event.Round, event.Collection)
+	switch event.Type {
+	case NewHolder:
+		return fmt.Sprintf("%s new holder %s", prefix, event.Address)
+	case HolderExited:
+		return fmt.Sprintf("%s %s no longer holds", prefix, event.Address)
+	case WhaleThresholdCrossed:
+		return fmt.Sprintf("%s %s holds %g, reaching the whale threshold of %g", prefix, event.Address, event.Balance, event.Threshold)
+	case SoldOut:
+		return fmt.Sprintf("%s sold out of the creator wallets", prefix)
+	}
+	return fmt.Sprintf("%s %s %s", prefix, event.Type, event.Address)
+}
+
+type multiSink []EventSink
+
+// Multi sends events to every sink, even when some of them fail.
+func Multi(sinks ...EventSink) EventSink {
+	return multiSink(sinks)
+}
+
+func (m multiSink) Send(ctx context.Context, events []Event) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Send(ctx, events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,166 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"sort"
+)
+
+// InventoryItem is a prize asset held by the treasury. Quantity is in base units,
+// so an NFT without decimals can be given away Quantity times.
+type InventoryItem struct {
+	AssetID  uint64
+	Name     string
+	UnitName string
+	Quantity uint64
+}
+
+// Inventory lists the prizes available, best first when prizes are assigned in draw order.
+type Inventory []InventoryItem
+
+// LoadInventory lists the assets held by the treasury, ordered by asset ID. Every asset is listed in base units,
+// fungible ones included, so pass the result through Only to keep just the NFTs to give away.
+func LoadInventory(ctx context.Context, client holders.AccountAssetsClient, treasury string) (Inventory, error) {
+	accountAssets, err := client.GetAccountAssets(ctx, treasury)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := make(Inventory, 0, len(accountAssets))
+	for _, accountAsset := range accountAssets {
+		inventory = append(inventory, InventoryItem{AssetID: accountAsset.AssetID, Quantity: accountAsset.Amount})
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].AssetID < inventory[j].AssetID
+	})
+	return inventory, nil
+}
+
+// Only keeps the items that are among assets and fills in their names,
+// e.g. to give away only the assets of a collection returned by GetAssetsByCollection.
+// Assets that aren't unique NFTs, with a total of 1 and no decimals, are left out.
+func (inv Inventory) Only(assets []holders.Asset) Inventory {
+	assetsByID := make(map[uint64]holders.Asset, len(assets))
+	for _, asset := range assets {
+		assetsByID[asset.AssetID] = asset
+	}
+
+	var kept Inventory
+	for _, item := range inv {
+		asset, found := assetsByID[item.AssetID]
+		if !found || asset.Total != 1 || asset.Decimals != 0 {
+			continue
+		}
+		item.Name = asset.Name
+		item.UnitName = asset.UnitName
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// Strategy chooses which of the available prizes a winner gets.
+type Strategy interface {
+	// Choose returns the index in available of the prize for the winner at place, starting from 1.
+	Choose(place int, winner holders.AssetHolding, available Inventory) int
+}
+
+type StrategyFunc func(place int, winner holders.AssetHolding, available Inventory) int
+
+func (f StrategyFunc) Choose(place int, winner holders.AssetHolding, available Inventory) int {
+	return f(place, winner, available)
+}
+
+// InDrawOrder gives each winner the first prize left, so the first winner gets the first item of the inventory.
+func InDrawOrder() Strategy {
+	return StrategyFunc(func(place int, winner holders.AssetHolding, available Inventory) int {
+		return 0
+	})
+}
+
+// AtRandom gives each winner a random prize. The same seed always assigns the same prizes.
+func AtRandom(seed string) Strategy {
+	source := holders.SeededRand(seed)
+	return StrategyFunc(func(place int, winner holders.AssetHolding, available Inventory) int {
+		return source.Intn(len(available))
+	})
+}
+
+// ByPreference gives each winner their most preferred asset that is still available, from asset IDs ranked by
+// address. Winners without a preference left are assigned by fallback, or in draw order when it is nil.
+func ByPreference(preferences map[string][]uint64, fallback Strategy) Strategy {
+	if fallback == nil {
+		fallback = InDrawOrder()
+	}
+	return StrategyFunc(func(place int, winner holders.AssetHolding, available Inventory) int {
+		for _, assetID := range preferences[winner.Address] {
+			for i, item := range available {
+				if item.AssetID == assetID {
+					return i
+				}
+			}
+		}
+		return fallback.Choose(place, winner, available)
+	})
+}
+
+// Assignment records which prize asset the winner at Place gets.
+type Assignment struct {
+	Place    int
+	Address  string
+	AssetID  uint64
+	Name     string
+	UnitName string
+}
+
+// AssignPrizes gives every winner one unit of an asset from the inventory, in draw order.
+// The inventory is not modified.
+func AssignPrizes(winners []holders.AssetHolding, inventory Inventory, strategy Strategy) ([]Assignment, error) {
+	available := make(Inventory, 0, len(inventory))
+	var units uint64
+	for _, item := range inventory {
+		if item.Quantity > 0 {
+			available = append(available, item)
+			units += item.Quantity
+		}
+	}
+	if units < uint64(len(winners)) {
+		return nil, fmt.Errorf("%d winners but only %d prizes in the inventory", len(winners), units)
+	}
+
+	assignments := make([]Assignment, 0, len(winners))
+	for i, winner := range winners {
+		choice := strategy.Choose(i+1, winner, available)
+		if choice < 0 || choice >= len(available) {
+			return nil, fmt.Errorf("strategy chose prize %d of %d for place %d", choice, len(available), i+1)
+		}
+
+		item := available[choice]
+		assignments = append(assignments, Assignment{
+			Place:    i + 1,
+			Address:  winner.Address,
+			AssetID:  item.AssetID,
+			Name:     item.Name,
+			UnitName: item.UnitName,
+		})
+		available[choice].Quantity--
+		if available[choice].Quantity == 0 {
+			available = append(available[:choice], available[choice+1:]...)
+		}
+	}
+	return assignments, nil
+}
+
+// NewAssignmentPayments pays each assigned prize as an NFT, with the same keys as NewPayments.
+func NewAssignmentPayments(payoutID string, assignments []Assignment) []Payment {
+	payments := make([]Payment, 0, len(assignments))
+	for _, assignment := range assignments {
+		payments = append(payments, Payment{
+			Key:     paymentKey(payoutID, assignment.Place),
+			Place:   assignment.Place,
+			Address: assignment.Address,
+			Prize:   NFT(assignment.AssetID),
+		})
+	}
+	return payments
+}
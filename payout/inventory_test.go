@@ -0,0 +1,108 @@
+package payout_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/payout"
+	"github.com/yellowbackground/holders/testdata"
+	"testing"
+)
+
+func TestLoadInventory(t *testing.T) {
+	client := &testdata.FakeCollectionClient{
+		AccountAssets: map[string][]holders.AccountAsset{
+			testdata.TestAccount1Address: {{AssetID: 9, Amount: 1}, {AssetID: 3, Amount: 2}, {AssetID: 5, Amount: 1}, {AssetID: 11, Amount: 5000000}},
+		},
+	}
+
+	inventory, err := payout.LoadInventory(context.Background(), client, testdata.TestAccount1Address)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payout.Inventory{{AssetID: 3, Quantity: 2}, {AssetID: 5, Quantity: 1}, {AssetID: 9, Quantity: 1}, {AssetID: 11, Quantity: 5000000}}, inventory)
+	// the fungible token and the edition of 10 are not unique prizes
+	assert.Equal(t, payout.Inventory{{AssetID: 9, Name: "Fren #9", UnitName: "MFER009", Quantity: 1}},
+		inventory.Only([]holders.Asset{
+			{AssetID: 3, Name: "Fren Edition", Total: 10},
+			{AssetID: 9, Name: "Fren #9", UnitName: "MFER009", Total: 1},
+			{AssetID: 10, Total: 1},
+			{AssetID: 11, Name: "Fren Token", Total: 1000000000000, Decimals: 6},
+		}))
+}
+
+func TestAssignPrizes(t *testing.T) {
+	winners := []holders.AssetHolding{{Address: "A"}, {Address: "B"}, {Address: "C"}}
+	inventory := payout.Inventory{
+		{AssetID: 1, Name: "Gold", Quantity: 1},
+		{AssetID: 2, Name: "Silver", Quantity: 2},
+		{AssetID: 3, Name: "Bronze", Quantity: 1},
+	}
+
+	tests := map[string]struct {
+		Strategy payout.Strategy
+		Want     []uint64
+	}{
+		"draw order": {
+			Strategy: payout.InDrawOrder(),
+			Want:     []uint64{1, 2, 2},
+		},
+		"preference": {
+			Strategy: payout.ByPreference(map[string][]uint64{
+				"A": {3},
+				"B": {3, 1},
+			}, nil),
+			Want: []uint64{3, 1, 2},
+		},
+		"preference falls back": {
+			Strategy: payout.ByPreference(map[string][]uint64{"C": {1}}, payout.StrategyFunc(func(place int, winner holders.AssetHolding, available payout.Inventory) int {
+				return len(available) - 1
+			})),
+			Want: []uint64{3, 2, 1},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assignments, err := payout.AssignPrizes(winners, inventory, test.Strategy)
+
+			assert.NoError(t, err)
+			var got []uint64
+			for i, assignment := range assignments {
+				assert.Equal(t, i+1, assignment.Place)
+				assert.Equal(t, winners[i].Address, assignment.Address)
+				got = append(got, assignment.AssetID)
+			}
+			assert.Equal(t, test.Want, got)
+			assert.Equal(t, uint64(2), inventory[1].Quantity)
+		})
+	}
+}
+
+func TestAssignPrizesAtRandomIsReproducible(t *testing.T) {
+	winners := []holders.AssetHolding{{Address: "A"}, {Address: "B"}, {Address: "C"}, {Address: "D"}}
+	inventory := payout.Inventory{{AssetID: 1, Quantity: 1}, {AssetID: 2, Quantity: 1}, {AssetID: 3, Quantity: 1}, {AssetID: 4, Quantity: 1}}
+
+	first, err := payout.AssignPrizes(winners, inventory, payout.AtRandom("week 1"))
+	assert.NoError(t, err)
+	second, err := payout.AssignPrizes(winners, inventory, payout.AtRandom("week 1"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assigned := make(map[uint64]bool)
+	for _, assignment := range first {
+		assigned[assignment.AssetID] = true
+	}
+	assert.Len(t, assigned, 4)
+}
+
+func TestAssignPrizesNotEnoughPrizes(t *testing.T) {
+	_, err := payout.AssignPrizes([]holders.AssetHolding{{Address: "A"}, {Address: "B"}}, payout.Inventory{{AssetID: 1, Quantity: 1}, {AssetID: 2}}, payout.InDrawOrder())
+
+	assert.EqualError(t, err, "2 winners but only 1 prizes in the inventory")
+}
+
+func TestNewAssignmentPayments(t *testing.T) {
+	payments := payout.NewAssignmentPayments("week-1", []payout.Assignment{{Place: 1, Address: "A", AssetID: 7}})
+
+	assert.Equal(t, []payout.Payment{{Key: "week-1/1", Place: 1, Address: "A", Prize: payout.NFT(7)}}, payments)
+}
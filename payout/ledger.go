@@ -0,0 +1,140 @@
+package payout
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusPending is recorded before a group is sent. Its outcome is unknown until it is confirmed or expires.
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	// StatusExpired groups passed their last valid round unconfirmed, so they can never be confirmed.
+	StatusExpired = "expired"
+)
+
+// LedgerEntry records the status of a group of payments. Later entries for the same group replace earlier ones.
+type LedgerEntry struct {
+	Time   time.Time
+	Status string
+	// TxIDs are the IDs of the transactions in the group, in the same order as Keys.
+	TxIDs []string
+	Keys  []string
+	// SignedGroup is kept while pending so the exact same transactions can be resent after a crash.
+	SignedGroup []byte `json:",omitempty"`
+	LastValid   uint64
+	Round       uint64 `json:",omitempty"`
+}
+
+// Ledger is an append-only file of JSON lines recording every payment group.
+// Each entry is synced to disk before the payout continues.
+type Ledger struct {
+	mutex   sync.Mutex
+	file    *os.File
+	groups  map[string]LedgerEntry
+	order   []string
+	paid    map[string]LedgerEntry
+	pending map[string]bool
+}
+
+// OpenLedger opens the ledger at path, creating it if needed, and replays its entries.
+func OpenLedger(path string) (*Ledger, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &Ledger{
+		file:    file,
+		groups:  make(map[string]LedgerEntry),
+		paid:    make(map[string]LedgerEntry),
+		pending: make(map[string]bool),
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if len(entry.TxIDs) == 0 {
+			file.Close()
+			return nil, fmt.Errorf("%s:%d: entry has no transactions", path, line)
+		}
+		ledger.apply(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return ledger, nil
+}
+
+func (l *Ledger) Close() error {
+	return l.file.Close()
+}
+
+// Paid returns the confirmed group that paid the payment with key.
+func (l *Ledger) Paid(key string) (LedgerEntry, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entry, found := l.paid[key]
+	return entry, found
+}
+
+// Pending returns the groups whose outcome is unknown, in the order they were recorded.
+func (l *Ledger) Pending() []LedgerEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var pending []LedgerEntry
+	for _, groupID := range l.order {
+		if l.pending[groupID] {
+			pending = append(pending, l.groups[groupID])
+		}
+	}
+	return pending
+}
+
+func (l *Ledger) record(entry LedgerEntry) error {
+	if len(entry.TxIDs) == 0 {
+		return errors.New("ledger entry has no transactions")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.apply(entry)
+	return nil
+}
+
+// apply updates the in-memory state with entry. Groups are identified by their first transaction ID.
+func (l *Ledger) apply(entry LedgerEntry) {
+	groupID := entry.TxIDs[0]
+	if _, found := l.groups[groupID]; !found {
+		l.order = append(l.order, groupID)
+	}
+	l.groups[groupID] = entry
+	l.pending[groupID] = entry.Status == StatusPending
+	if entry.Status == StatusConfirmed {
+		for _, key := range entry.Keys {
+			l.paid[key] = entry
+		}
+	}
+}
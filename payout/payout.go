@@ -0,0 +1,235 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+	"time"
+)
+
+// MaxGroupSize is the most transactions Algorand accepts in one atomic group.
+const MaxGroupSize = 16
+
+const (
+	ResultPaid        = "paid"
+	ResultAlreadyPaid = "already paid"
+	ResultNotOptedIn  = "not opted in"
+)
+
+type Result struct {
+	Payment Payment
+	Status  string
+	TxID    string
+	Round   uint64
+}
+
+// Payer sends prizes from the signer's account.
+type Payer struct {
+	signer      Signer
+	optInClient holders.AssetOptInClient
+	sender      holders.TransactionSender
+	ledger      *Ledger
+
+	Note []byte
+	// GroupSize is the number of payments sent in each atomic group. Defaults to MaxGroupSize.
+	GroupSize int
+}
+
+func NewPayer(signer Signer, optInClient holders.AssetOptInClient, sender holders.TransactionSender, ledger *Ledger) *Payer {
+	return &Payer{
+		signer:      signer,
+		optInClient: optInClient,
+		sender:      sender,
+		ledger:      ledger,
+	}
+}
+
+// Pay first settles groups left pending by an earlier run, then pays every payment that has not been paid.
+// Payments of assets the winner has not opted in to are skipped and can be paid by a later run.
+// The results are in the same order as payments. On error the results so far are returned;
+// running Pay again with the same ledger carries on where it stopped.
+func (p *Payer) Pay(ctx context.Context, payments []Payment) ([]Result, error) {
+	seen := make(map[string]bool, len(payments))
+	for _, payment := range payments {
+		if seen[payment.Key] {
+			return nil, fmt.Errorf("duplicate payment key %q", payment.Key)
+		}
+		seen[payment.Key] = true
+	}
+
+	if err := p.settlePending(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(payments))
+	var unpaid []int
+	for i, payment := range payments {
+		results[i].Payment = payment
+		if entry, found := p.ledger.Paid(payment.Key); found {
+			results[i].Status = ResultAlreadyPaid
+			results[i].TxID = txIDOf(entry, payment.Key)
+			results[i].Round = entry.Round
+			continue
+		}
+		if !payment.Prize.IsAlgo() {
+			optedIn, err := p.optInClient.IsOptedIn(ctx, payment.Address, payment.Prize.AssetID)
+			if err != nil {
+				return results, fmt.Errorf("checking opt-in of %s: %w", payment.Address, err)
+			}
+			if !optedIn {
+				results[i].Status = ResultNotOptedIn
+				continue
+			}
+		}
+		unpaid = append(unpaid, i)
+	}
+	if len(unpaid) == 0 {
+		return results, nil
+	}
+
+	params, err := p.sender.SuggestedParams(ctx)
+	if err != nil {
+		return results, err
+	}
+	groupSize := p.GroupSize
+	if groupSize < 1 || groupSize > MaxGroupSize {
+		groupSize = MaxGroupSize
+	}
+	for start := 0; start < len(unpaid); start += groupSize {
+		group := unpaid[start:min(start+groupSize, len(unpaid))]
+		groupPayments := make([]Payment, 0, len(group))
+		for _, i := range group {
+			groupPayments = append(groupPayments, payments[i])
+		}
+
+		entry, err := p.payGroup(ctx, groupPayments, params)
+		if err != nil {
+			return results, err
+		}
+		for j, i := range group {
+			results[i].Status = ResultPaid
+			results[i].TxID = entry.TxIDs[j]
+			results[i].Round = entry.Round
+		}
+	}
+	return results, nil
+}
+
+// payGroup records the signed group as pending before sending it, so a crash at any point leaves enough
+// in the ledger to find out whether it was paid.
+func (p *Payer) payGroup(ctx context.Context, payments []Payment, params types.SuggestedParams) (LedgerEntry, error) {
+	txns := make([]types.Transaction, 0, len(payments))
+	for _, payment := range payments {
+		txn, err := p.buildTransaction(payment, params)
+		if err != nil {
+			return LedgerEntry{}, fmt.Errorf("payment %s: %w", payment.Key, err)
+		}
+		txns = append(txns, txn)
+	}
+	groupID, err := crypto.ComputeGroupID(txns)
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+	for i := range txns {
+		txns[i].Group = groupID
+	}
+
+	signed, err := p.signer.SignTransactions(ctx, txns)
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("signing: %w", err)
+	}
+	if err := verifySigned(txns, signed); err != nil {
+		return LedgerEntry{}, err
+	}
+
+	entry := LedgerEntry{
+		Time:        time.Now().UTC(),
+		Status:      StatusPending,
+		SignedGroup: signed,
+		LastValid:   uint64(params.LastRoundValid),
+	}
+	for i, txn := range txns {
+		entry.TxIDs = append(entry.TxIDs, crypto.GetTxID(txn))
+		entry.Keys = append(entry.Keys, payments[i].Key)
+	}
+	if err := p.ledger.record(entry); err != nil {
+		return LedgerEntry{}, err
+	}
+
+	return p.send(ctx, entry)
+}
+
+// settlePending finds out what happened to groups an earlier run left pending. Groups still within their
+// validity window are resent as they were signed, which the network ignores if they were already confirmed.
+func (p *Payer) settlePending(ctx context.Context) error {
+	for _, entry := range p.ledger.Pending() {
+		round, err := p.sender.ConfirmedRound(ctx, entry.TxIDs[0])
+		if err != nil {
+			return err
+		}
+		if round > 0 {
+			if err := p.recordOutcome(entry, StatusConfirmed, round); err != nil {
+				return err
+			}
+			continue
+		}
+
+		currentRound, err := p.sender.CurrentRound(ctx)
+		if err != nil {
+			return err
+		}
+		if currentRound > entry.LastValid {
+			if err := p.recordOutcome(entry, StatusExpired, 0); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := p.send(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Payer) send(ctx context.Context, entry LedgerEntry) (LedgerEntry, error) {
+	if err := p.sender.SendTransactions(ctx, entry.SignedGroup); err != nil {
+		return LedgerEntry{}, fmt.Errorf("sending group %s: %w", entry.TxIDs[0], err)
+	}
+	round, err := p.sender.WaitForConfirmation(ctx, entry.TxIDs[0])
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("waiting for group %s: %w", entry.TxIDs[0], err)
+	}
+	confirmed := entry
+	confirmed.Status = StatusConfirmed
+	confirmed.Round = round
+	confirmed.SignedGroup = nil
+	confirmed.Time = time.Now().UTC()
+	return confirmed, p.ledger.record(confirmed)
+}
+
+func (p *Payer) recordOutcome(entry LedgerEntry, status string, round uint64) error {
+	entry.Status = status
+	entry.Round = round
+	entry.SignedGroup = nil
+	entry.Time = time.Now().UTC()
+	return p.ledger.record(entry)
+}
+
+func (p *Payer) buildTransaction(payment Payment, params types.SuggestedParams) (types.Transaction, error) {
+	if payment.Prize.IsAlgo() {
+		return future.MakePaymentTxn(p.signer.Address(), payment.Address, payment.Prize.Amount, p.Note, "", params)
+	}
+	return future.MakeAssetTransferTxn(p.signer.Address(), payment.Address, payment.Prize.Amount, p.Note, params, "", payment.Prize.AssetID)
+}
+
+func txIDOf(entry LedgerEntry, key string) string {
+	for i, entryKey := range entry.Keys {
+		if entryKey == key {
+			return entry.TxIDs[i]
+		}
+	}
+	return ""
+}
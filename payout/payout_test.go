@@ -0,0 +1,182 @@
+package payout_test
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/mnemonic"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/payout"
+	"github.com/yellowbackground/holders/testdata"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTreasury(t *testing.T) (payout.Signer, string) {
+	account := crypto.GenerateAccount()
+	words, err := mnemonic.FromPrivateKey(account.PrivateKey)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "treasury.mnemonic")
+	assert.NoError(t, os.WriteFile(path, []byte(words+"\n"), 0600))
+
+	signer, err := payout.NewMnemonicFileSigner(path)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address.String(), signer.Address())
+	return signer, path
+}
+
+func newSender() *testdata.FakeTransactionSender {
+	return &testdata.FakeTransactionSender{
+		Round: 1000,
+		Params: types.SuggestedParams{
+			FirstRoundValid: 1000,
+			LastRoundValid:  2000,
+			GenesisID:       "testnet-v1.0",
+			GenesisHash:     make([]byte, 32),
+			MinFee:          1000,
+		},
+	}
+}
+
+var winners = []holders.AssetHolding{
+	{Address: testdata.TestAccount1Address},
+	{Address: testdata.TestAccount2Address},
+	{Address: testdata.TestAccount1Address},
+}
+
+var prizes = []payout.Prize{payout.Algo(5000000), payout.Asset(7, 100), payout.NFT(8)}
+
+func TestNewPayments(t *testing.T) {
+	payments, err := payout.NewPayments("week-1", winners, prizes)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payout.Payment{Key: "week-1/2", Place: 2, Address: testdata.TestAccount2Address, Prize: payout.Asset(7, 100)}, payments[1])
+
+	_, err = payout.NewPayments("week-1", winners, prizes[:1])
+	assert.EqualError(t, err, "3 winners but 1 prizes")
+}
+
+func TestPay(t *testing.T) {
+	signer, _ := newTreasury(t)
+	sender := newSender()
+	optIns := &testdata.FakeCollectionClient{OptedIn: map[string]bool{testdata.TestAccount2Address: true}}
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	payments, _ := payout.NewPayments("week-1", winners, prizes)
+
+	ledger, err := payout.OpenLedger(ledgerPath)
+	assert.NoError(t, err)
+	results, err := payout.NewPayer(signer, optIns, sender, ledger).Pay(context.Background(), payments)
+	assert.NoError(t, err)
+	assert.NoError(t, ledger.Close())
+
+	assert.Equal(t, []string{payout.ResultPaid, payout.ResultPaid, payout.ResultNotOptedIn}, statuses(results))
+	assert.Equal(t, uint64(1001), results[0].Round)
+	assert.Len(t, sender.Sent, 1)
+
+	// the winner of the NFT opts in and the payout runs again
+	optIns.OptedIn[testdata.TestAccount1Address] = true
+	ledger, err = payout.OpenLedger(ledgerPath)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	again, err := payout.NewPayer(signer, optIns, sender, ledger).Pay(context.Background(), payments)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{payout.ResultAlreadyPaid, payout.ResultAlreadyPaid, payout.ResultPaid}, statuses(again))
+	assert.Equal(t, results[0].TxID, again[0].TxID)
+	assert.Len(t, sender.Sent, 2)
+}
+
+func TestPayResumesAfterCrash(t *testing.T) {
+	tests := map[string]struct {
+		// Landed is whether the group sent before the crash was confirmed.
+		Landed       bool
+		CurrentRound uint64
+		WantSent     int
+		WantResult   string
+	}{
+		"group was confirmed": {
+			Landed:       true,
+			CurrentRound: 1500,
+			WantSent:     1,
+			WantResult:   payout.ResultAlreadyPaid,
+		},
+		"group may still land so it is resent": {
+			CurrentRound: 1500,
+			WantSent:     2,
+			WantResult:   payout.ResultAlreadyPaid,
+		},
+		"group expired so it is paid again": {
+			CurrentRound: 2001,
+			WantSent:     2,
+			WantResult:   payout.ResultPaid,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			signer, _ := newTreasury(t)
+			sender := newSender()
+			sender.FailWait = true
+			sender.DropSent = !test.Landed
+			optIns := &testdata.FakeCollectionClient{}
+			payments, _ := payout.NewPayments("week-1", winners[:1], prizes[:1])
+			ledger, err := payout.OpenLedger(filepath.Join(t.TempDir(), "ledger.jsonl"))
+			assert.NoError(t, err)
+			defer ledger.Close()
+
+			_, err = payout.NewPayer(signer, optIns, sender, ledger).Pay(context.Background(), payments)
+			assert.ErrorContains(t, err, "connection lost")
+			assert.Len(t, ledger.Pending(), 1)
+
+			sender.FailWait = false
+			sender.DropSent = false
+			sender.Round = test.CurrentRound
+			sender.Params.FirstRoundValid = types.Round(test.CurrentRound)
+			sender.Params.LastRoundValid = types.Round(test.CurrentRound + 1000)
+			results, err := payout.NewPayer(signer, optIns, sender, ledger).Pay(context.Background(), payments)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{test.WantResult}, statuses(results))
+			assert.Len(t, sender.Sent, test.WantSent)
+			assert.Empty(t, ledger.Pending())
+			if test.WantSent == 2 && test.WantResult == payout.ResultAlreadyPaid {
+				assert.Equal(t, sender.Sent[0], sender.Sent[1])
+			}
+		})
+	}
+}
+
+func TestPayRejectsUnsignedTransactions(t *testing.T) {
+	// cat echoes the unsigned transactions back
+	signer := payout.NewCommandSigner(testdata.TestAccount1Address, "cat")
+	sender := newSender()
+	payments, _ := payout.NewPayments("week-1", winners[:1], prizes[:1])
+	ledger, err := payout.OpenLedger(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	_, err = payout.NewPayer(signer, &testdata.FakeCollectionClient{}, sender, ledger).Pay(context.Background(), payments)
+
+	assert.EqualError(t, err, "transaction 0 is not signed")
+	assert.Empty(t, sender.Sent)
+	assert.Empty(t, ledger.Pending())
+}
+
+func TestNewMnemonicFileSignerChecksPermissions(t *testing.T) {
+	_, path := newTreasury(t)
+	assert.NoError(t, os.Chmod(path, 0644))
+
+	_, err := payout.NewMnemonicFileSigner(path)
+
+	assert.ErrorContains(t, err, "must only be readable by its owner")
+}
+
+func statuses(results []payout.Result) []string {
+	var statuses []string
+	for _, result := range results {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
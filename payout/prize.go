@@ -0,0 +1,72 @@
+// Package payout pays raffle winners their prizes, signing through a pluggable Signer and recording every
+// payment in a ledger so an interrupted payout can be resumed without paying anyone twice.
+package payout
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders"
+)
+
+// Prize is an amount of ALGO or of an asset sent from the treasury.
+type Prize struct {
+	// AssetID is 0 for ALGO.
+	AssetID uint64
+	// Amount is in microAlgos for ALGO, otherwise in base units of the asset.
+	Amount uint64
+}
+
+func Algo(microAlgos uint64) Prize {
+	return Prize{Amount: microAlgos}
+}
+
+func Asset(assetID uint64, amount uint64) Prize {
+	return Prize{AssetID: assetID, Amount: amount}
+}
+
+// NFT is a single unit of an asset. Use Asset for NFTs with decimals.
+func NFT(assetID uint64) Prize {
+	return Prize{AssetID: assetID, Amount: 1}
+}
+
+func (p Prize) IsAlgo() bool {
+	return p.AssetID == 0
+}
+
+func (p Prize) String() string {
+	if p.IsAlgo() {
+		return fmt.Sprintf("%d microAlgos", p.Amount)
+	}
+	return fmt.Sprintf("%d of asset %d", p.Amount, p.AssetID)
+}
+
+// Payment is a prize owed to a winner. Key identifies it in the ledger.
+type Payment struct {
+	Key     string
+	Place   int
+	Address string
+	Prize   Prize
+}
+
+// NewPayments gives each winner the prize for their place, the first prize to the first winner.
+// Keys are made of the payout ID and the place, so paying out the same raffle again never pays a place twice,
+// even if its winner changed.
+func NewPayments(payoutID string, winners []holders.AssetHolding, prizes []Prize) ([]Payment, error) {
+	if len(winners) != len(prizes) {
+		return nil, fmt.Errorf("%d winners but %d prizes", len(winners), len(prizes))
+	}
+
+	payments := make([]Payment, 0, len(winners))
+	for i, winner := range winners {
+		payments = append(payments, Payment{
+			Key:     paymentKey(payoutID, i+1),
+			Place:   i + 1,
+			Address: winner.Address,
+			Prize:   prizes[i],
+		})
+	}
+	return payments, nil
+}
+
+func paymentKey(payoutID string, place int) string {
+	return fmt.Sprintf("%s/%d", payoutID, place)
+}
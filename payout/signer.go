@@ -0,0 +1,182 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/client/kmd"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/mnemonic"
+	"github.com/algorand/go-algorand-sdk/types"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Signer signs the transactions of the treasury account.
+type Signer interface {
+	// Address is the account the signer signs for.
+	Address() string
+	// SignTransactions returns the signed transactions concatenated in the same order.
+	SignTransactions(ctx context.Context, txns []types.Transaction) ([]byte, error)
+}
+
+type mnemonicSigner struct {
+	address    string
+	privateKey ed25519.PrivateKey
+}
+
+// NewMnemonicFileSigner signs with the account whose 25 word mnemonic is in path.
+func NewMnemonicFileSigner(path string) (Signer, error) {
+	privateKey, err := LoadMnemonicFile(path)
+	if err != nil {
+		return nil, err
+	}
+	address, err := crypto.GenerateAddressFromSK(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &mnemonicSigner{address: address.String(), privateKey: privateKey}, nil
+}
+
+// LoadMnemonicFile reads the private key of the 25 word mnemonic in path.
+// The file must not be readable by other users.
+func LoadMnemonicFile(path string) (ed25519.PrivateKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("mnemonic file %s must only be readable by its owner, found permissions %s", path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := mnemonic.ToPrivateKey(strings.Join(strings.Fields(string(data)), " "))
+	if err != nil {
+		return nil, fmt.Errorf("mnemonic file %s: %w", path, err)
+	}
+	return privateKey, nil
+}
+
+func (s *mnemonicSigner) Address() string {
+	return s.address
+}
+
+func (s *mnemonicSigner) SignTransactions(ctx context.Context, txns []types.Transaction) ([]byte, error) {
+	var signed []byte
+	for _, txn := range txns {
+		_, stx, err := crypto.SignTransaction(s.privateKey, txn)
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, stx...)
+	}
+	return signed, nil
+}
+
+type kmdSigner struct {
+	client   kmd.Client
+	walletID string
+	password string
+	address  string
+}
+
+// NewKMDSigner signs with the key of address held in a KMD wallet.
+func NewKMDSigner(client kmd.Client, walletName string, password string, address string) (Signer, error) {
+	wallets, err := client.ListWallets()
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range wallets.Wallets {
+		if wallet.Name == walletName {
+			return &kmdSigner{client: client, walletID: wallet.ID, password: password, address: address}, nil
+		}
+	}
+	return nil, fmt.Errorf("kmd wallet %q not found", walletName)
+}
+
+func (s *kmdSigner) Address() string {
+	return s.address
+}
+
+func (s *kmdSigner) SignTransactions(ctx context.Context, txns []types.Transaction) ([]byte, error) {
+	handle, err := s.client.InitWalletHandle(s.walletID, s.password)
+	if err != nil {
+		return nil, err
+	}
+	defer s.client.ReleaseWalletHandle(handle.WalletHandleToken)
+
+	var signed []byte
+	for _, txn := range txns {
+		res, err := s.client.SignTransaction(handle.WalletHandleToken, s.password, txn)
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, res.SignedTransaction...)
+	}
+	return signed, nil
+}
+
+type commandSigner struct {
+	address string
+	name    string
+	args    []string
+}
+
+// NewCommandSigner hands signing to an external program, e.g. a hardware wallet or remote signing service.
+// The program reads the unsigned transactions from stdin in the format `goal clerk sign` reads,
+// and writes the signed transactions to stdout.
+func NewCommandSigner(address string, name string, args ...string) Signer {
+	return &commandSigner{address: address, name: name, args: args}
+}
+
+func (s *commandSigner) Address() string {
+	return s.address
+}
+
+func (s *commandSigner) SignTransactions(ctx context.Context, txns []types.Transaction) ([]byte, error) {
+	var stdin bytes.Buffer
+	for _, txn := range txns {
+		stdin.Write(msgpack.Encode(types.SignedTxn{Txn: txn}))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.name, s.args...)
+	cmd.Stdin = &stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("signer %s: %w: %s", s.name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// verifySigned checks that signed holds exactly txns, each signed, so a signer can't swap or drop transactions.
+func verifySigned(txns []types.Transaction, signed []byte) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(signed))
+	for i, txn := range txns {
+		var stx types.SignedTxn
+		if err := decoder.Decode(&stx); err != nil {
+			return fmt.Errorf("decoding signed transaction %d: %w", i, err)
+		}
+		if crypto.GetTxID(stx.Txn) != crypto.GetTxID(txn) {
+			return fmt.Errorf("signed transaction %d does not match the transaction to sign", i)
+		}
+		if stx.Sig == (types.Signature{}) && stx.Msig.Blank() && stx.Lsig.Blank() {
+			return fmt.Errorf("transaction %d is not signed", i)
+		}
+	}
+
+	var extra types.SignedTxn
+	if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+		return errors.New("signer returned more transactions than it was given")
+	}
+	return nil
+}
@@ -2,18 +2,63 @@ package holders
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"github.com/mroth/weightedrand/v2"
-	"github.com/rs/zerolog/log"
+	"math/bits"
+	"math/rand"
+	"sort"
 )
 
 type RaffleConfig struct {
-	RandSeed string
+	// RandSeed makes the draw reproducible. Winners are picked from the global random source when empty.
+	RandSeed              string
+	NumberOfWinners       int
+	ExcludedWinnerWallets []string
+	SetBonuses            []SetBonus
+}
+
+// SetBonus multiplies the tickets of every holder who completes the set. Multiplier must be at least 1.
+type SetBonus struct {
+	Set        Set
+	Multiplier uint64
 }
 
 type WeightedCollection struct {
 	Collection Collection
 	Weight     uint64
+	// AssetWeights optionally overrides the weight of individual assets in the collection.
+	AssetWeights AssetWeigher
+	// AmountWeights converts amounts held into tickets. Defaults to RawAmount.
+	AmountWeights AmountWeigher
+}
+
+func (wc WeightedCollection) assetWeight(asset Asset) uint64 {
+	if wc.AssetWeights == nil {
+		return defaultAssetWeight
+	}
+	return wc.AssetWeights.AssetWeight(asset)
+}
+
+func (wc WeightedCollection) amountWeight(holding AssetHolding) uint64 {
+	if wc.AmountWeights == nil {
+		return RawAmount.AmountWeight(holding)
+	}
+	return wc.AmountWeights.AmountWeight(holding)
+}
+
+// HoldingWeight is the number of tickets a holding in this collection is worth.
+// It returns an error when the product of the weights doesn't fit in a uint64.
+func (wc WeightedCollection) HoldingWeight(holding AssetHolding) (uint64, error) {
+	hi, weight := bits.Mul64(wc.Weight, wc.assetWeight(holding.Asset()))
+	if hi == 0 {
+		hi, weight = bits.Mul64(weight, wc.amountWeight(holding))
+	}
+	if hi != 0 {
+		return 0, fmt.Errorf("%s: weight of asset %d held by %s overflows uint64", wc.Collection.Name, holding.AssetID, holding.Address)
+	}
+	return weight, nil
 }
 
 func RunWeightedCollectionRaffle(ctx context.Context, client CollectionClient, weightedCollections []WeightedCollection, numberOfWinners int, concurrency int, excludedWinnerWallets []string) ([]AssetHolding, error) {
@@ -24,13 +69,50 @@ func RunWeightedCollectionRaffle(ctx context.Context, client CollectionClient, w
 		return nil, err
 	}
 
-	weightedTickets := createWeightedLotteryTickets(assetsHoldingsByCollection, weightedCollections)
+	return RunWeightedRaffle(assetsHoldingsByCollection, weightedCollections, RaffleConfig{
+		NumberOfWinners:       numberOfWinners,
+		ExcludedWinnerWallets: excludedWinnerWallets,
+	})
+}
+
+// RunWeightedRaffle picks winners from holdings that have already been fetched, e.g. from a snapshot.
+// Holdings of collections not in weightedCollections get no tickets.
+func RunWeightedRaffle(assetsHoldingsByCollection map[string][]AssetHolding, weightedCollections []WeightedCollection, config RaffleConfig) ([]AssetHolding, error) {
+	weightedTickets, err := createWeightedLotteryTickets(assetsHoldingsByCollection, weightedCollections)
+	if err != nil {
+		return nil, err
+	}
+	weightedTickets, err = applySetBonuses(weightedTickets, assetsHoldingsByCollection, config.SetBonuses)
+	if err != nil {
+		return nil, err
+	}
+
 	chooser, err := weightedrand.NewChooser(weightedTickets...)
 	if err != nil {
 		return nil, err
 	}
 
-	return pickUniqueWinners(weightedTickets, chooser, numberOfWinners, excludedWinnerWallets)
+	return pickUniqueWinners(weightedTickets, chooser, newPicker(config.RandSeed), config.NumberOfWinners, config.ExcludedWinnerWallets)
+}
+
+type picker func(chooser *weightedrand.Chooser[AssetHolding, uint64]) AssetHolding
+
+func newPicker(randSeed string) picker {
+	if randSeed == "" {
+		return func(chooser *weightedrand.Chooser[AssetHolding, uint64]) AssetHolding {
+			return chooser.Pick()
+		}
+	}
+	source := SeededRand(randSeed)
+	return func(chooser *weightedrand.Chooser[AssetHolding, uint64]) AssetHolding {
+		return chooser.PickSource(source)
+	}
+}
+
+// SeededRand returns a random source that always produces the same sequence for the same seed.
+func SeededRand(seed string) *rand.Rand {
+	sum := sha256.Sum256([]byte(seed))
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
 }
 
 func extractCollections(weightedCollections []WeightedCollection) []Collection {
@@ -41,7 +123,7 @@ func extractCollections(weightedCollections []WeightedCollection) []Collection {
 	return collections
 }
 
-func pickUniqueWinners(weightedTickets []weightedrand.Choice[AssetHolding, uint64], chooser *weightedrand.Chooser[AssetHolding, uint64], numberOfWinners int, excludedWallets []string) ([]AssetHolding, error) {
+func pickUniqueWinners(weightedTickets []weightedrand.Choice[AssetHolding, uint64], chooser *weightedrand.Chooser[AssetHolding, uint64], pick picker, numberOfWinners int, excludedWallets []string) ([]AssetHolding, error) {
 	selectedWinners := make(map[AssetHolding]bool)
 	var winners []AssetHolding
 
@@ -59,7 +141,7 @@ func pickUniqueWinners(weightedTickets []weightedrand.Choice[AssetHolding, uint6
 			return nil, fmt.Errorf("not enough unique assets to select %d winners", numberOfWinners)
 		}
 
-		winner := chooser.Pick()
+		winner := pick(chooser)
 
 		if !isExcludedWallet(winner.Address) && !selectedWinners[winner] {
 			selectedWinners[winner] = true
@@ -88,25 +170,37 @@ func removePickedAsset(tickets []weightedrand.Choice[AssetHolding, uint64], asse
 	return updatedTickets
 }
 
-func createWeightedLotteryTickets(assetsByCollection map[string][]AssetHolding, collections []WeightedCollection) []weightedrand.Choice[AssetHolding, uint64] {
+func createWeightedLotteryTickets(assetsByCollection map[string][]AssetHolding, collections []WeightedCollection) ([]weightedrand.Choice[AssetHolding, uint64], error) {
 	var choices []weightedrand.Choice[AssetHolding, uint64]
 
-	for collectionName, holdings := range assetsByCollection {
+	collectionNames := make([]string, 0, len(assetsByCollection))
+	for collectionName := range assetsByCollection {
+		collectionNames = append(collectionNames, collectionName)
+	}
+	// tickets are created in a stable order so that seeded raffles are reproducible
+	sort.Strings(collectionNames)
+
+	for _, collectionName := range collectionNames {
+		holdings := assetsByCollection[collectionName]
 		collection, found := findWeightedCollection(collections, collectionName)
 		if !found {
-			log.Fatal().Msgf("collection %s not found", collectionName)
+			continue
 		}
 
 		for _, holding := range holdings {
+			weight, err := collection.HoldingWeight(holding)
+			if err != nil {
+				return nil, err
+			}
 			weightedHolding := weightedrand.Choice[AssetHolding, uint64]{
 				Item:   holding,
-				Weight: collection.Weight * holding.Amount,
+				Weight: weight,
 			}
 			choices = append(choices, weightedHolding)
 		}
 	}
 
-	return choices
+	return choices, nil
 }
 
 func findWeightedCollection(weightedCollections []WeightedCollection, collectionName string) (WeightedCollection, bool) {
@@ -117,3 +211,27 @@ func findWeightedCollection(weightedCollections []WeightedCollection, collection
 	}
 	return WeightedCollection{}, false
 }
+
+func applySetBonuses(tickets []weightedrand.Choice[AssetHolding, uint64], assetsByCollection map[string][]AssetHolding, setBonuses []SetBonus) ([]weightedrand.Choice[AssetHolding, uint64], error) {
+	for _, setBonus := range setBonuses {
+		if setBonus.Multiplier == 0 {
+			return nil, fmt.Errorf("set bonus %s: multiplier must be at least 1", setBonus.Set.Name)
+		}
+		completeSetHolders := make(map[string]bool)
+		for _, address := range GetCompleteSetHolders(assetsByCollection, setBonus.Set) {
+			completeSetHolders[address] = true
+		}
+
+		for i := range tickets {
+			if !completeSetHolders[tickets[i].Item.Address] {
+				continue
+			}
+			hi, weight := bits.Mul64(tickets[i].Weight, setBonus.Multiplier)
+			if hi != 0 {
+				return nil, fmt.Errorf("set bonus %s: weight of asset %d held by %s overflows uint64", setBonus.Set.Name, tickets[i].Item.AssetID, tickets[i].Item.Address)
+			}
+			tickets[i].Weight = weight
+		}
+	}
+	return tickets, nil
+}
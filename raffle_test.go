@@ -0,0 +1,42 @@
+package holders
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRunWeightedRaffleWithSeed(t *testing.T) {
+	holdings := map[string][]AssetHolding{
+		"A": {{Address: "1", Amount: 1, AssetID: 1}, {Address: "2", Amount: 1, AssetID: 2}, {Address: "3", Amount: 1, AssetID: 3}},
+		"B": {{Address: "4", Amount: 1, AssetID: 4}, {Address: "5", Amount: 1, AssetID: 5}},
+	}
+	weightedCollections := []WeightedCollection{
+		{Collection: Collection{Name: "A"}, Weight: 1},
+		{Collection: Collection{Name: "B"}, Weight: 2},
+	}
+	config := RaffleConfig{RandSeed: "week 42", NumberOfWinners: 3}
+
+	first, err := RunWeightedRaffle(holdings, weightedCollections, config)
+	assert.NoError(t, err)
+	assert.Len(t, first, 3)
+
+	for i := 0; i < 5; i++ {
+		again, err := RunWeightedRaffle(holdings, weightedCollections, config)
+		assert.NoError(t, err)
+		assert.Equal(t, first, again)
+	}
+}
+
+func TestRunWeightedRaffleIgnoresUnweightedCollections(t *testing.T) {
+	holdings := map[string][]AssetHolding{
+		"A": {{Address: "1", Amount: 1, AssetID: 1}},
+		"B": {{Address: "2", Amount: 1, AssetID: 2}},
+	}
+
+	winners, err := RunWeightedRaffle(holdings, []WeightedCollection{{Collection: Collection{Name: "A"}, Weight: 1}}, RaffleConfig{NumberOfWinners: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, holdings["A"], winners)
+
+	_, err = RunWeightedRaffle(holdings, []WeightedCollection{{Collection: Collection{Name: "A"}, Weight: 1}}, RaffleConfig{NumberOfWinners: 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+// Package roles works out which community roles each user should have from the holdings of their linked addresses.
+// It doesn't talk to any chat platform: it returns the roles to add and remove, for a bot to apply.
+package roles
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/rules"
+	"github.com/yellowbackground/holders/snapshot"
+	"sort"
+)
+
+// Role is granted to users whose combined holdings satisfy Rule.
+type Role struct {
+	Name string
+	Rule *rules.Rule
+	// HeldSince, when set, also requires the rule to be satisfied by every snapshot from this round on,
+	// e.g. for an OG role. The history must include a snapshot at or before the round.
+	HeldSince uint64
+}
+
+// User is a member of the community with the addresses they have linked and the roles they have now.
+type User struct {
+	ID        string
+	Addresses []string
+	Roles     []string
+}
+
+// Change is the roles a user should gain and lose.
+type Change struct {
+	UserID string
+	Add    []string
+	Remove []string
+}
+
+type Engine struct {
+	roles []Role
+}
+
+// NewEngine manages the roles given. Roles a user has that the engine doesn't manage are never removed.
+func NewEngine(roles []Role) (*Engine, error) {
+	for _, role := range roles {
+		if role.Rule == nil {
+			return nil, fmt.Errorf("role %q has no rule", role.Name)
+		}
+	}
+	return &Engine{roles: roles}, nil
+}
+
+// Evaluate compares the roles users should have at current with the roles they have, returning the users
+// that need changes in the order given. history holds older snapshots, needed by roles with HeldSince.
+func (e *Engine) Evaluate(users []User, current snapshot.Snapshot, history []snapshot.Snapshot) ([]Change, error) {
+	history = append([]snapshot.Snapshot{}, history...)
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Round < history[j].Round
+	})
+
+	holdingsByUser := byUser(users, current.Holdings)
+	historyByUser := make([]map[string]map[string][]holders.AssetHolding, len(history))
+	for i, snap := range history {
+		historyByUser[i] = byUser(users, snap.Holdings)
+	}
+
+	var changes []Change
+	for _, user := range users {
+		has := make(map[string]bool)
+		for _, role := range user.Roles {
+			has[role] = true
+		}
+
+		change := Change{UserID: user.ID}
+		for _, role := range e.roles {
+			qualifies := role.Rule.Evaluate(user.ID, holdingsByUser[user.ID]).Eligible
+			if qualifies && role.HeldSince > 0 {
+				from, err := heldSinceIndex(history, role)
+				if err != nil {
+					return nil, err
+				}
+				for _, holdings := range historyByUser[from:] {
+					qualifies = qualifies && role.Rule.Evaluate(user.ID, holdings[user.ID]).Eligible
+				}
+			}
+
+			switch {
+			case qualifies && !has[role.Name]:
+				change.Add = append(change.Add, role.Name)
+			case !qualifies && has[role.Name]:
+				change.Remove = append(change.Remove, role.Name)
+			}
+		}
+		if len(change.Add) > 0 || len(change.Remove) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// heldSinceIndex is the index of the last snapshot at or before the role's HeldSince round.
+func heldSinceIndex(history []snapshot.Snapshot, role Role) (int, error) {
+	index := sort.Search(len(history), func(i int) bool {
+		return history[i].Round > role.HeldSince
+	}) - 1
+	if index < 0 {
+		return 0, fmt.Errorf("role %q: no snapshot at or before round %d", role.Name, role.HeldSince)
+	}
+	return index, nil
+}
+
+// byUser groups holdings by the users that linked their address. Holdings are given the user's ID as address,
+// so a rule sees the combined holdings of all of a user's addresses. An address linked twice is counted once.
+func byUser(users []User, holdingsByCollection map[string][]holders.AssetHolding) map[string]map[string][]holders.AssetHolding {
+	usersByAddress := make(map[string][]string)
+	for _, user := range users {
+		linked := make(map[string]bool)
+		for _, address := range user.Addresses {
+			if !linked[address] {
+				linked[address] = true
+				usersByAddress[address] = append(usersByAddress[address], user.ID)
+			}
+		}
+	}
+
+	result := make(map[string]map[string][]holders.AssetHolding)
+	for collectionName, holdings := range holdingsByCollection {
+		for _, holding := range holdings {
+			for _, userID := range usersByAddress[holding.Address] {
+				if result[userID] == nil {
+					result[userID] = make(map[string][]holders.AssetHolding)
+				}
+				holding.Address = userID
+				result[userID][collectionName] = append(result[userID][collectionName], holding)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package roles_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/roles"
+	"github.com/yellowbackground/holders/rules"
+	"github.com/yellowbackground/holders/snapshot"
+	"testing"
+)
+
+var managedRoles = []roles.Role{
+	{Name: "Flambo Owner", Rule: rules.MustParse(`>= 1 "Yieldlings Flambos"`)},
+	{Name: "Whale", Rule: rules.MustParse("total >= 25")},
+	{Name: "OG", Rule: rules.MustParse("any Yieldlings"), HeldSince: 1000},
+}
+
+func newEngine(t *testing.T) *roles.Engine {
+	engine, err := roles.NewEngine(managedRoles)
+	assert.NoError(t, err)
+	return engine
+}
+
+func holdings(round uint64, yieldlings map[string]uint64, flambos map[string]uint64) snapshot.Snapshot {
+	snap := snapshot.Snapshot{Round: round, Holdings: map[string][]holders.AssetHolding{}}
+	for address, amount := range yieldlings {
+		snap.Holdings["Yieldlings"] = append(snap.Holdings["Yieldlings"], holders.AssetHolding{Address: address, AssetID: 1, Amount: amount})
+	}
+	for address, amount := range flambos {
+		snap.Holdings["Yieldlings Flambos"] = append(snap.Holdings["Yieldlings Flambos"], holders.AssetHolding{Address: address, AssetID: 2, Amount: amount})
+	}
+	return snap
+}
+
+func TestEvaluate(t *testing.T) {
+	history := []snapshot.Snapshot{
+		holdings(1500, map[string]uint64{"A": 1, "C": 1}, nil),
+		holdings(900, map[string]uint64{"A": 1, "C": 1, "D": 1}, nil),
+	}
+	current := holdings(2000, map[string]uint64{"A": 1, "B1": 20, "C": 1, "D": 1}, map[string]uint64{"B2": 5})
+
+	tests := map[string]struct {
+		User roles.User
+		Want []roles.Change
+	}{
+		"gains the roles it qualifies for": {
+			User: roles.User{ID: "alice", Addresses: []string{"A"}},
+			Want: []roles.Change{{UserID: "alice", Add: []string{"OG"}}},
+		},
+		"holdings of linked addresses are combined": {
+			User: roles.User{ID: "bob", Addresses: []string{"B1", "B2"}},
+			Want: []roles.Change{{UserID: "bob", Add: []string{"Flambo Owner", "Whale"}}},
+		},
+		"roles already held are unchanged": {
+			User: roles.User{ID: "carol", Addresses: []string{"C"}, Roles: []string{"OG"}},
+		},
+		"roles no longer qualified for are removed": {
+			User: roles.User{ID: "dave", Addresses: []string{"D"}, Roles: []string{"Whale", "OG", "Moderator"}},
+			Want: []roles.Change{{UserID: "dave", Remove: []string{"Whale", "OG"}}},
+		},
+		"an address linked twice is counted once": {
+			User: roles.User{ID: "bob", Addresses: []string{"B1", "B1"}},
+		},
+		"users without holdings": {
+			User: roles.User{ID: "erin"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			changes, err := newEngine(t).Evaluate([]roles.User{test.User}, current, history)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.Want, changes)
+		})
+	}
+}
+
+func TestEvaluateWithoutHistory(t *testing.T) {
+	current := holdings(2000, map[string]uint64{"A": 1}, nil)
+
+	_, err := newEngine(t).Evaluate([]roles.User{{ID: "alice", Addresses: []string{"A"}}}, current, nil)
+
+	assert.EqualError(t, err, `role "OG": no snapshot at or before round 1000`)
+}
+
+func TestNewEngineWithoutRule(t *testing.T) {
+	_, err := roles.NewEngine([]roles.Role{{Name: "Whale"}})
+
+	assert.EqualError(t, err, `role "Whale" has no rule`)
+}
@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenAny
+	tokenTotal
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of rule"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+var keywords = map[string]tokenKind{
+	"and":   tokenAnd,
+	"or":    tokenOr,
+	"not":   tokenNot,
+	"any":   tokenAny,
+	"total": tokenTotal,
+}
+
+var operatorAliases = map[string]string{
+	"≥": ">=",
+	"≤": "<=",
+	"≠": "!=",
+	"=": "==",
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, value: ")", pos: i})
+			i++
+		case r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			if i == len(runes) {
+				return nil, &SyntaxError{Pos: start, Message: "unterminated collection name"}
+			}
+			tokens = append(tokens, token{kind: tokenString, value: string(runes[start+1 : i]), pos: start})
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, &SyntaxError{Pos: i, Message: fmt.Sprintf("unexpected %q, did you mean %q", r, string([]rune{r, r}))}
+			}
+			kind := tokenAnd
+			if r == '|' {
+				kind = tokenOr
+			}
+			tokens = append(tokens, token{kind: kind, value: string([]rune{r, r}), pos: i})
+			i += 2
+		case strings.ContainsRune("<>=!≥≤≠", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' && strings.ContainsRune("<>=!", r) {
+				i++
+			}
+			value := string(runes[start:i])
+			if alias, found := operatorAliases[value]; found {
+				value = alias
+			}
+			if value == "!" {
+				tokens = append(tokens, token{kind: tokenNot, value: value, pos: start})
+				continue
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: value, pos: start})
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune("_.-", runes[i])) {
+				i++
+			}
+			value := string(runes[start:i])
+			kind, isKeyword := keywords[strings.ToLower(value)]
+			if !isKeyword {
+				kind = tokenIdent
+			}
+			tokens = append(tokens, token{kind: kind, value: value, pos: start})
+		default:
+			return nil, &SyntaxError{Pos: i, Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
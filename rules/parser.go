@@ -0,0 +1,278 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type node interface {
+	eval(amounts map[string]float64) (bool, []string)
+	collections(seen map[string]bool)
+	String() string
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(amounts map[string]float64) (bool, []string) {
+	leftOK, leftReasons := n.left.eval(amounts)
+	if !leftOK {
+		return false, nil
+	}
+	rightOK, rightReasons := n.right.eval(amounts)
+	if !rightOK {
+		return false, nil
+	}
+	return true, append(leftReasons, rightReasons...)
+}
+
+func (n andNode) collections(seen map[string]bool) {
+	n.left.collections(seen)
+	n.right.collections(seen)
+}
+
+func (n andNode) String() string {
+	return "(" + n.left.String() + " AND " + n.right.String() + ")"
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(amounts map[string]float64) (bool, []string) {
+	leftOK, leftReasons := n.left.eval(amounts)
+	rightOK, rightReasons := n.right.eval(amounts)
+	var reasons []string
+	if leftOK {
+		reasons = append(reasons, leftReasons...)
+	}
+	if rightOK {
+		reasons = append(reasons, rightReasons...)
+	}
+	return leftOK || rightOK, reasons
+}
+
+func (n orNode) collections(seen map[string]bool) {
+	n.left.collections(seen)
+	n.right.collections(seen)
+}
+
+func (n orNode) String() string {
+	return "(" + n.left.String() + " OR " + n.right.String() + ")"
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n notNode) eval(amounts map[string]float64) (bool, []string) {
+	ok, _ := n.operand.eval(amounts)
+	if ok {
+		return false, nil
+	}
+	return true, []string{"NOT " + n.operand.String()}
+}
+
+func (n notNode) collections(seen map[string]bool) {
+	n.operand.collections(seen)
+}
+
+func (n notNode) String() string {
+	return "NOT " + n.operand.String()
+}
+
+// conditionNode compares the amount held in a collection, or in all collections when total is set.
+type conditionNode struct {
+	collection string
+	total      bool
+	operator   string
+	value      float64
+}
+
+func (n conditionNode) eval(amounts map[string]float64) (bool, []string) {
+	var held float64
+	if n.total {
+		for _, amount := range amounts {
+			held += amount
+		}
+	} else {
+		held = amounts[n.collection]
+	}
+
+	var ok bool
+	switch n.operator {
+	case ">=":
+		ok = held >= n.value
+	case ">":
+		ok = held > n.value
+	case "<=":
+		ok = held <= n.value
+	case "<":
+		ok = held < n.value
+	case "==":
+		ok = held == n.value
+	case "!=":
+		ok = held != n.value
+	}
+	if !ok {
+		return false, nil
+	}
+	return true, []string{fmt.Sprintf("%s (holds %s)", n.String(), formatAmount(held))}
+}
+
+func (n conditionNode) collections(seen map[string]bool) {
+	if !n.total {
+		seen[n.collection] = true
+	}
+}
+
+func (n conditionNode) String() string {
+	subject := "total"
+	if !n.total {
+		subject = strconv.Quote(n.collection)
+	}
+	return fmt.Sprintf("%s %s %s", subject, n.operator, formatAmount(n.value))
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLeftParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, &SyntaxError{Pos: closing.pos, Message: fmt.Sprintf("expected \")\" to close \"(\" at column %d, got %s", t.pos+1, closing)}
+		}
+		return inner, nil
+	case tokenAny:
+		name := p.next()
+		if name.kind != tokenString && name.kind != tokenIdent {
+			return nil, &SyntaxError{Pos: name.pos, Message: fmt.Sprintf("expected collection name after \"any\", got %s", name)}
+		}
+		return conditionNode{collection: name.value, operator: ">=", value: 1}, nil
+	case tokenTotal:
+		return p.parseComparison(conditionNode{total: true}, t)
+	case tokenString, tokenIdent:
+		return p.parseComparison(conditionNode{collection: t.value}, t)
+	case tokenOperator:
+		return p.parseQuantifier(t)
+	default:
+		return nil, &SyntaxError{Pos: t.pos, Message: fmt.Sprintf("expected collection name, \"any\", \"total\", \"NOT\" or \"(\", got %s", t)}
+	}
+}
+
+func (p *parser) parseComparison(condition conditionNode, subject token) (node, error) {
+	operator := p.next()
+	if operator.kind != tokenOperator {
+		hint := ""
+		if subject.kind == tokenIdent && (operator.kind == tokenIdent || operator.kind == tokenString) {
+			hint = "; quote collection names that contain spaces"
+		}
+		return nil, &SyntaxError{Pos: operator.pos, Message: fmt.Sprintf("expected comparison operator after %s, got %s%s", subject, operator, hint)}
+	}
+	value, err := p.parseNumber(operator)
+	if err != nil {
+		return nil, err
+	}
+	condition.operator = operator.value
+	condition.value = value
+	return condition, nil
+}
+
+// parseQuantifier parses the "operator number collection" form, e.g. >= 2 Yieldlings.
+func (p *parser) parseQuantifier(operator token) (node, error) {
+	value, err := p.parseNumber(operator)
+	if err != nil {
+		return nil, err
+	}
+	subject := p.next()
+	switch subject.kind {
+	case tokenTotal:
+		return conditionNode{total: true, operator: operator.value, value: value}, nil
+	case tokenString, tokenIdent:
+		return conditionNode{collection: subject.value, operator: operator.value, value: value}, nil
+	default:
+		return nil, &SyntaxError{Pos: subject.pos, Message: fmt.Sprintf("expected collection name or \"total\" after %s, got %s", formatAmount(value), subject)}
+	}
+}
+
+func (p *parser) parseNumber(operator token) (float64, error) {
+	number := p.next()
+	if number.kind != tokenNumber {
+		return 0, &SyntaxError{Pos: number.pos, Message: fmt.Sprintf("expected number after %q, got %s", operator.value, number)}
+	}
+	value, err := strconv.ParseFloat(number.value, 64)
+	if err != nil {
+		return 0, &SyntaxError{Pos: number.pos, Message: fmt.Sprintf("invalid number %q", number.value)}
+	}
+	return value, nil
+}
+
+func formatAmount(amount float64) string {
+	return strings.TrimSuffix(strings.TrimRight(strconv.FormatFloat(amount, 'f', 6, 64), "0"), ".")
+}
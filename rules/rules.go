@@ -0,0 +1,137 @@
+// Package rules implements a small expression language for eligibility across collections, e.g.
+//
+//	"Yieldlings" >= 2 AND "Yieldlings Flambos" >= 1 OR any "Best Frens"
+//
+// A condition compares the whole units an address holds in a collection, or across all collections
+// with total, using >=, >, <=, <, == or != (≥, ≤ and ≠ also work). The comparison may come first,
+// as in ">= 2 Yieldlings". "any C" is short for "C >= 1". Conditions are combined
+// with AND, OR and NOT (also &&, || and !) and grouped with parentheses. AND binds tighter than OR.
+// Collection names may be unquoted when they are a single word.
+package rules
+
+import (
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports a problem in a rule at a character position, counted from 0.
+type SyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Message)
+}
+
+// Rule is a parsed eligibility expression.
+type Rule struct {
+	Source string
+	root   node
+}
+
+func Parse(src string) (*Rule, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: next.pos, Message: fmt.Sprintf("unexpected %s, expected AND, OR or end of rule", next)}
+	}
+
+	return &Rule{Source: src, root: root}, nil
+}
+
+func MustParse(src string) *Rule {
+	rule, err := Parse(src)
+	if err != nil {
+		panic(fmt.Sprintf("rules: Parse(%q): %v", src, err))
+	}
+	return rule
+}
+
+// Collections returns the collection names referenced by the rule.
+func (r *Rule) Collections() []string {
+	seen := make(map[string]bool)
+	r.root.collections(seen)
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that every collection referenced by the rule is known.
+func (r *Rule) Validate(collectionNames []string) error {
+	known := make(map[string]bool)
+	for _, name := range collectionNames {
+		known[name] = true
+	}
+	var unknown []string
+	for _, name := range r.Collections() {
+		if !known[name] {
+			unknown = append(unknown, strconv.Quote(name))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown collection %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func (r *Rule) String() string {
+	return r.root.String()
+}
+
+// Qualification is the verdict of a rule for one address with the conditions that decided it.
+type Qualification struct {
+	Address  string
+	Eligible bool
+	Reasons  []string
+}
+
+// Evaluate applies the rule to the holdings of a single address.
+func (r *Rule) Evaluate(address string, holdingsByCollection map[string][]holders.AssetHolding) Qualification {
+	amounts := amountsByCollection(holdingsByCollection)[address]
+	eligible, reasons := r.root.eval(amounts)
+	return Qualification{Address: address, Eligible: eligible, Reasons: reasons}
+}
+
+// EligibleAddresses applies the rule to every holder and returns those that qualify, ordered by address.
+func (r *Rule) EligibleAddresses(holdingsByCollection map[string][]holders.AssetHolding) []Qualification {
+	var qualifications []Qualification
+	for address, amounts := range amountsByCollection(holdingsByCollection) {
+		if eligible, reasons := r.root.eval(amounts); eligible {
+			qualifications = append(qualifications, Qualification{Address: address, Eligible: true, Reasons: reasons})
+		}
+	}
+	sort.Slice(qualifications, func(i, j int) bool {
+		return qualifications[i].Address < qualifications[j].Address
+	})
+	return qualifications
+}
+
+// amountsByCollection sums the whole units held by each address in each collection.
+func amountsByCollection(holdingsByCollection map[string][]holders.AssetHolding) map[string]map[string]float64 {
+	amounts := make(map[string]map[string]float64)
+	for collectionName, holdings := range holdingsByCollection {
+		for _, holding := range holdings {
+			if amounts[holding.Address] == nil {
+				amounts[holding.Address] = make(map[string]float64)
+			}
+			amounts[holding.Address][collectionName] += holding.DecimalAmount()
+		}
+	}
+	return amounts
+}
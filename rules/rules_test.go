@@ -0,0 +1,77 @@
+package rules_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/rules"
+	"testing"
+)
+
+var holdings = map[string][]holders.AssetHolding{
+	"Yieldlings": {
+		{Address: "A", Amount: 1, AssetID: 1},
+		{Address: "A", Amount: 1, AssetID: 2},
+		{Address: "B", Amount: 1, AssetID: 3},
+	},
+	"Yieldlings Flambos": {
+		{Address: "A", Amount: 1, AssetID: 4},
+		{Address: "B", Amount: 1, AssetID: 5},
+	},
+	"Best Frens": {
+		{Address: "C", Amount: 1, AssetID: 6},
+	},
+}
+
+func TestEligibleAddresses(t *testing.T) {
+	tests := map[string]struct {
+		GotRule            string
+		WantQualifications []rules.Qualification
+	}{
+		"and or": {
+			GotRule: `≥ 2 Yieldlings AND ≥ 1 "Yieldlings Flambos" OR any "Best Frens"`,
+			WantQualifications: []rules.Qualification{
+				{Address: "A", Eligible: true, Reasons: []string{`"Yieldlings" >= 2 (holds 2)`, `"Yieldlings Flambos" >= 1 (holds 1)`}},
+				{Address: "C", Eligible: true, Reasons: []string{`"Best Frens" >= 1 (holds 1)`}},
+			},
+		},
+		"not and parentheses": {
+			GotRule: `NOT (Yieldlings > 1 || any "Best Frens") && total >= 2`,
+			WantQualifications: []rules.Qualification{
+				{Address: "B", Eligible: true, Reasons: []string{`NOT ("Yieldlings" > 1 OR "Best Frens" >= 1)`, `total >= 2 (holds 2)`}},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := rules.Parse(test.GotRule)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.WantQualifications, rule.EligibleAddresses(holdings))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		`Yieldlings >=`:              `column 14: expected number after ">=", got end of rule`,
+		`Best Frens >= 1`:            `column 6: expected comparison operator after "Best", got "Frens"; quote collection names that contain spaces`,
+		`(any Yieldlings`:            `column 16: expected ")" to close "(" at column 1, got end of rule`,
+		`any Yieldlings any "Frens"`: `column 16: unexpected "any", expected AND, OR or end of rule`,
+		`"Yieldlings >= 1`:           `column 1: unterminated collection name`,
+		`Yieldlings >= 1 & any B`:    `column 17: unexpected '&', did you mean "&&"`,
+	}
+	for rule, wantErr := range tests {
+		t.Run(rule, func(t *testing.T) {
+			_, err := rules.Parse(rule)
+
+			assert.EqualError(t, err, wantErr)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rule := rules.MustParse(`any Yieldlings OR any Flambo`)
+
+	assert.Equal(t, []string{"Flambo", "Yieldlings"}, rule.Collections())
+	assert.EqualError(t, rule.Validate([]string{"Yieldlings"}), `unknown collection "Flambo"`)
+}
@@ -0,0 +1,428 @@
+// Package server serves collection holders, raffles and snapshots as a JSON HTTP API.
+// The API is described by the OpenAPI spec served at /openapi.yaml.
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/rs/zerolog/log"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/cache"
+	"github.com/yellowbackground/holders/snapshot"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+const (
+	defaultMaxWinners     = 100
+	defaultRequestTimeout = time.Minute
+	maxRequestBodyBytes   = 1 << 20
+)
+
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+type Options struct {
+	// Collections are the collections served, and raffled with their weights.
+	Collections []holders.WeightedCollection
+	// SnapshotDir holds the snapshot files served under /snapshots. Snapshots are not served when empty.
+	SnapshotDir string
+	// HoldingsTTL is how long holdings are cached in memory. Zero disables caching of holdings.
+	HoldingsTTL time.Duration
+	// Concurrency is the number of collections fetched at once. Defaults to 1.
+	Concurrency int
+	// RequestTimeout limits how long a request may spend fetching data. Defaults to a minute.
+	RequestTimeout time.Duration
+	// MaxWinners is the most winners a raffle may draw. Defaults to 100.
+	MaxWinners int
+}
+
+// Server is an http.Handler serving the API.
+type Server struct {
+	client          holders.CollectionClient
+	lookup          *holders.HoldingsLookup
+	options         Options
+	collections     map[string]holders.WeightedCollection
+	collectionNames []string
+	mux             *http.ServeMux
+}
+
+// New serves the collections in options from client. Collection assets are cached in memory
+// for cache.DefaultTTLs.Assets and holdings for options.HoldingsTTL.
+func New(client holders.CollectionClient, options Options) *Server {
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = defaultRequestTimeout
+	}
+	if options.MaxWinners <= 0 {
+		options.MaxWinners = defaultMaxWinners
+	}
+
+	cachingClient := cache.NewCollectionClient(client, cache.NewMemoryBackend(), cache.TTLs{
+		Assets:    cache.DefaultTTLs.Assets,
+		Holdings:  options.HoldingsTTL,
+		Ownership: cache.DefaultTTLs.Ownership,
+	})
+	s := &Server{
+		client:      cachingClient,
+		options:     options,
+		collections: make(map[string]holders.WeightedCollection, len(options.Collections)),
+		mux:         http.NewServeMux(),
+	}
+	if accountClient, ok := client.(holders.AccountAssetsClient); ok {
+		s.lookup = holders.NewHoldingsLookup(cachingClient, accountClient)
+		// cachingClient expires the assets after cache.DefaultTTLs.Assets
+		s.lookup.TTL = -1
+	}
+	for _, weightedCollection := range options.Collections {
+		s.collections[weightedCollection.Collection.Name] = weightedCollection
+		s.collectionNames = append(s.collectionNames, weightedCollection.Collection.Name)
+	}
+
+	s.mux.HandleFunc("GET /openapi.yaml", s.getOpenAPISpec)
+	s.mux.HandleFunc("GET /collections", s.getCollections)
+	s.mux.HandleFunc("GET /collections/{name}/holders", s.getCollectionHolders)
+	s.mux.HandleFunc("GET /addresses/{address}/holdings", s.getAddressHoldings)
+	s.mux.HandleFunc("POST /raffles", s.postRaffle)
+	s.mux.HandleFunc("GET /snapshots", s.getSnapshots)
+	s.mux.HandleFunc("GET /snapshots/{name}", s.getSnapshot)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves handler on addr until ctx is done, then waits up to shutdownTimeout
+// for requests in flight to finish.
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler, shutdownTimeout time.Duration) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errChan; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+type ErrorResponse struct {
+	Error string
+}
+
+type CollectionsResponse struct {
+	Collections []string
+}
+
+type HoldersResponse struct {
+	Collection string
+	Holdings   []holders.AssetHolding
+}
+
+type AddressHoldingsResponse struct {
+	Address string
+	// Holdings are grouped by collection name. Collections the address does not hold are omitted.
+	Holdings map[string][]holders.AssetHolding
+}
+
+type RaffleRequest struct {
+	// Seed makes the draw reproducible and is required.
+	Seed    string
+	Winners int
+	// Collections limits the raffle to some of the served collections. All are included when empty.
+	Collections []string
+	// Snapshot draws from a stored snapshot instead of the current holders.
+	Snapshot          string
+	ExcludedAddresses []string
+	// IncludeCreators allows the creator addresses of the collections to win.
+	IncludeCreators bool
+}
+
+type RaffleResponse struct {
+	Seed    string
+	Winners []holders.AssetHolding
+}
+
+type SnapshotSummary struct {
+	Name            string
+	Round           uint64
+	CreatedAt       time.Time
+	CollectionsHash string
+}
+
+type SnapshotsResponse struct {
+	Snapshots []SnapshotSummary
+}
+
+func (s *Server) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+func (s *Server) getCollections(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, CollectionsResponse{Collections: s.collectionNames})
+}
+
+func (s *Server) getCollectionHolders(w http.ResponseWriter, r *http.Request) {
+	weightedCollection, found := s.collections[r.PathValue("name")]
+	if !found {
+		writeError(w, http.StatusNotFound, "collection %q not found", r.PathValue("name"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.options.RequestTimeout)
+	defer cancel()
+	holdings, err := s.client.GetAssetHoldingsByCollection(ctx, weightedCollection.Collection)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, HoldersResponse{Collection: weightedCollection.Collection.Name, Holdings: holdings})
+}
+
+func (s *Server) getAddressHoldings(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	if _, err := types.DecodeAddress(address); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid address %q: %v", address, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.options.RequestTimeout)
+	defer cancel()
+	holdings, err := s.holdingsByAddress(ctx, address)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, AddressHoldingsResponse{Address: address, Holdings: holdings})
+}
+
+// holdingsByAddress looks up the account's assets when the client supports it,
+// otherwise it searches the holders of every collection.
+func (s *Server) holdingsByAddress(ctx context.Context, address string) (map[string][]holders.AssetHolding, error) {
+	collections := s.selectedCollections(nil)
+	if s.lookup != nil {
+		return s.lookup.GetHoldingsByAddress(ctx, address, collections)
+	}
+
+	holdingsByCollection, err := holders.GetAssetHoldingsByCollection(ctx, s.client, collections, s.options.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]holders.AssetHolding)
+	for collectionName, holdings := range holdingsByCollection {
+		for _, holding := range holdings {
+			if holding.Address == address {
+				result[collectionName] = append(result[collectionName], holding)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) postRaffle(w http.ResponseWriter, r *http.Request) {
+	var request RaffleRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return
+	}
+	if err := s.validateRaffleRequest(request); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.options.RequestTimeout)
+	defer cancel()
+
+	collections := s.selectedCollections(request.Collections)
+	var holdingsByCollection map[string][]holders.AssetHolding
+	if request.Snapshot != "" {
+		snap, status, err := s.loadSnapshot(request.Snapshot)
+		if err != nil {
+			writeError(w, status, "%v", err)
+			return
+		}
+		holdingsByCollection = make(map[string][]holders.AssetHolding, len(collections))
+		for _, collection := range collections {
+			holdingsByCollection[collection.Name] = snap.Holdings[collection.Name]
+		}
+	} else {
+		var err error
+		holdingsByCollection, err = holders.GetAssetHoldingsByCollection(ctx, s.client, collections, s.options.Concurrency)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	excluded := request.ExcludedAddresses
+	weightedCollections := make([]holders.WeightedCollection, 0, len(collections))
+	for _, collection := range collections {
+		weightedCollections = append(weightedCollections, s.collections[collection.Name])
+		if !request.IncludeCreators {
+			excluded = append(excluded, collection.Addresses...)
+		}
+	}
+
+	winners, err := holders.RunWeightedRaffle(holdingsByCollection, weightedCollections, holders.RaffleConfig{
+		RandSeed:              request.Seed,
+		NumberOfWinners:       request.Winners,
+		ExcludedWinnerWallets: excluded,
+	})
+	if err != nil {
+		// the raffle only fails when nobody can win
+		writeError(w, http.StatusUnprocessableEntity, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, RaffleResponse{Seed: request.Seed, Winners: winners})
+}
+
+func (s *Server) validateRaffleRequest(request RaffleRequest) error {
+	if strings.TrimSpace(request.Seed) == "" {
+		return errors.New("Seed is required")
+	}
+	if request.Winners < 1 || request.Winners > s.options.MaxWinners {
+		return fmt.Errorf("Winners must be between 1 and %d", s.options.MaxWinners)
+	}
+	for _, collectionName := range request.Collections {
+		if _, found := s.collections[collectionName]; !found {
+			return fmt.Errorf("collection %q not found", collectionName)
+		}
+	}
+	for _, address := range request.ExcludedAddresses {
+		if _, err := types.DecodeAddress(address); err != nil {
+			return fmt.Errorf("invalid excluded address %q: %v", address, err)
+		}
+	}
+	if request.Snapshot != "" && s.options.SnapshotDir == "" {
+		return errors.New("snapshots are not enabled")
+	}
+	return nil
+}
+
+// selectedCollections returns the named collections, or all of them when names is empty.
+func (s *Server) selectedCollections(names []string) []holders.Collection {
+	if len(names) == 0 {
+		names = s.collectionNames
+	}
+	collections := make([]holders.Collection, 0, len(names))
+	for _, name := range names {
+		collections = append(collections, s.collections[name].Collection)
+	}
+	return collections
+}
+
+func (s *Server) getSnapshots(w http.ResponseWriter, r *http.Request) {
+	if s.options.SnapshotDir == "" {
+		writeError(w, http.StatusNotFound, "snapshots are not enabled")
+		return
+	}
+
+	paths, err := filepath.Glob(filepath.Join(s.options.SnapshotDir, "*.json"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	response := SnapshotsResponse{Snapshots: []SnapshotSummary{}}
+	for _, path := range paths {
+		snap, err := snapshot.Load(path)
+		if err != nil {
+			// skip files that aren't snapshots rather than failing the whole listing
+			log.Warn().Err(err).Str("path", path).Msg("skipping unreadable snapshot")
+			continue
+		}
+		response.Snapshots = append(response.Snapshots, SnapshotSummary{
+			Name:            strings.TrimSuffix(filepath.Base(path), ".json"),
+			Round:           snap.Round,
+			CreatedAt:       snap.CreatedAt,
+			CollectionsHash: snap.CollectionsHash,
+		})
+	}
+	sort.Slice(response.Snapshots, func(i, j int) bool {
+		return response.Snapshots[i].Name < response.Snapshots[j].Name
+	})
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	if s.options.SnapshotDir == "" {
+		writeError(w, http.StatusNotFound, "snapshots are not enabled")
+		return
+	}
+	snap, status, err := s.loadSnapshot(r.PathValue("name"))
+	if err != nil {
+		writeError(w, status, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, snap)
+}
+
+// loadSnapshot loads a snapshot by name, returning the status to respond with on failure.
+func (s *Server) loadSnapshot(name string) (snapshot.Snapshot, int, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return snapshot.Snapshot{}, http.StatusBadRequest, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	snap, err := snapshot.Load(filepath.Join(s.options.SnapshotDir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot.Snapshot{}, http.StatusNotFound, fmt.Errorf("snapshot %q not found", name)
+	}
+	if err != nil {
+		return snapshot.Snapshot{}, http.StatusInternalServerError, err
+	}
+	return snap, http.StatusOK, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Error().Err(err).Msg("failed to write response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, ErrorResponse{Error: fmt.Sprintf(format, args...)})
+}
+
+// writeInternalError logs err and hides its details from the client.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Error().Err(err).Str("path", r.URL.Path).Msg("request failed")
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, http.StatusGatewayTimeout, "timed out fetching holders")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
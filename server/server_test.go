@@ -0,0 +1,221 @@
+package server_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/server"
+	"github.com/yellowbackground/holders/snapshot"
+	"github.com/yellowbackground/holders/testdata"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var weightedCollections = []holders.WeightedCollection{
+	{Collection: holders.Collection{Name: "Mostly Frens", Addresses: []string{testdata.TestAccount1Address}}, Weight: 1},
+	{Collection: holders.Collection{Name: "Best Frens"}, Weight: 2},
+}
+
+func newFakeClient() *testdata.FakeCollectionClient {
+	return &testdata.FakeCollectionClient{
+		Assets: map[string][]holders.Asset{
+			"Mostly Frens": {{AssetID: 1, UnitName: "MFER001"}},
+			"Best Frens":   {{AssetID: 2, UnitName: "BFER001"}},
+		},
+		Holdings: map[string][]holders.AssetHolding{
+			"Mostly Frens": {
+				{Address: testdata.TestAccount1Address, Amount: 1, AssetID: 1, UnitName: "MFER001"},
+				{Address: testdata.TestAccount2Address, Amount: 1, AssetID: 1, UnitName: "MFER001"},
+			},
+			"Best Frens": {{Address: testdata.TestAccount2Address, Amount: 1, AssetID: 2, UnitName: "BFER001"}},
+		},
+		AccountAssets: map[string][]holders.AccountAsset{
+			testdata.TestAccount2Address: {{AssetID: 1, Amount: 1}, {AssetID: 2, Amount: 1}},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	snapshotDir := t.TempDir()
+	assert.NoError(t, snapshot.Save(filepath.Join(snapshotDir, "week-1.json"), snapshot.Snapshot{
+		Version: snapshot.Version,
+		Round:   100,
+		Holdings: map[string][]holders.AssetHolding{
+			"Best Frens": {{Address: testdata.TestAccount1Address, Amount: 1, AssetID: 2}},
+		},
+	}))
+
+	tests := map[string]struct {
+		Method     string
+		Path       string
+		Body       string
+		WantStatus int
+		WantBody   string
+	}{
+		"collections": {
+			Method:     http.MethodGet,
+			Path:       "/collections",
+			WantStatus: http.StatusOK,
+			WantBody:   `{"Collections":["Mostly Frens","Best Frens"]}`,
+		},
+		"collection holders": {
+			Method:     http.MethodGet,
+			Path:       "/collections/Best%20Frens/holders",
+			WantStatus: http.StatusOK,
+			WantBody:   `{"Collection":"Best Frens","Holdings":[{"Name":"","UnitName":"BFER001","Address":"` + testdata.TestAccount2Address + `","Amount":1,"AssetID":2,"Decimals":0}]}`,
+		},
+		"unknown collection": {
+			Method:     http.MethodGet,
+			Path:       "/collections/nope/holders",
+			WantStatus: http.StatusNotFound,
+			WantBody:   `{"Error":"collection \"nope\" not found"}`,
+		},
+		"address holdings": {
+			Method:     http.MethodGet,
+			Path:       "/addresses/" + testdata.TestAccount2Address + "/holdings",
+			WantStatus: http.StatusOK,
+			WantBody: `{"Address":"` + testdata.TestAccount2Address + `","Holdings":{` +
+				`"Best Frens":[{"Name":"","UnitName":"BFER001","Address":"` + testdata.TestAccount2Address + `","Amount":1,"AssetID":2,"Decimals":0}],` +
+				`"Mostly Frens":[{"Name":"","UnitName":"MFER001","Address":"` + testdata.TestAccount2Address + `","Amount":1,"AssetID":1,"Decimals":0}]}}`,
+		},
+		"invalid address": {
+			Method:     http.MethodGet,
+			Path:       "/addresses/nope/holdings",
+			WantStatus: http.StatusBadRequest,
+		},
+		"raffle excludes creators": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Seed":"week 1","Winners":1,"Collections":["Mostly Frens"]}`,
+			WantStatus: http.StatusOK,
+			WantBody:   `{"Seed":"week 1","Winners":[{"Name":"","UnitName":"MFER001","Address":"` + testdata.TestAccount2Address + `","Amount":1,"AssetID":1,"Decimals":0}]}`,
+		},
+		"raffle from snapshot": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Seed":"week 1","Winners":1,"Snapshot":"week-1","Collections":["Best Frens"]}`,
+			WantStatus: http.StatusOK,
+			WantBody:   `{"Seed":"week 1","Winners":[{"Name":"","UnitName":"","Address":"` + testdata.TestAccount1Address + `","Amount":1,"AssetID":2,"Decimals":0}]}`,
+		},
+		"raffle without seed": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Winners":1}`,
+			WantStatus: http.StatusBadRequest,
+			WantBody:   `{"Error":"Seed is required"}`,
+		},
+		"raffle with too many winners": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Seed":"x","Winners":1000}`,
+			WantStatus: http.StatusBadRequest,
+			WantBody:   `{"Error":"Winners must be between 1 and 100"}`,
+		},
+		"raffle with unknown field": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Seed":"x","Winners":1,"Nope":true}`,
+			WantStatus: http.StatusBadRequest,
+			WantBody:   `{"Error":"invalid request body: json: unknown field \"Nope\""}`,
+		},
+		"raffle with unknown snapshot": {
+			Method:     http.MethodPost,
+			Path:       "/raffles",
+			Body:       `{"Seed":"x","Winners":1,"Snapshot":"nope"}`,
+			WantStatus: http.StatusNotFound,
+			WantBody:   `{"Error":"snapshot \"nope\" not found"}`,
+		},
+		"snapshots": {
+			Method:     http.MethodGet,
+			Path:       "/snapshots",
+			WantStatus: http.StatusOK,
+			WantBody:   `{"Snapshots":[{"Name":"week-1","Round":100,"CreatedAt":"0001-01-01T00:00:00Z","CollectionsHash":""}]}`,
+		},
+		"snapshot": {
+			Method:     http.MethodGet,
+			Path:       "/snapshots/week-1",
+			WantStatus: http.StatusOK,
+		},
+		"snapshot outside the directory": {
+			Method:     http.MethodGet,
+			Path:       "/snapshots/..%2Fsecret",
+			WantStatus: http.StatusBadRequest,
+		},
+		"wrong method": {
+			Method:     http.MethodDelete,
+			Path:       "/collections",
+			WantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	underTest := server.New(newFakeClient(), server.Options{Collections: weightedCollections, SnapshotDir: snapshotDir})
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			underTest.ServeHTTP(recorder, httptest.NewRequest(test.Method, test.Path, strings.NewReader(test.Body)))
+
+			assert.Equal(t, test.WantStatus, recorder.Code, recorder.Body.String())
+			if test.WantBody != "" {
+				assert.JSONEq(t, test.WantBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestServerCachesHoldings(t *testing.T) {
+	client := newFakeClient()
+	underTest := server.New(client, server.Options{Collections: weightedCollections, HoldingsTTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		underTest.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/collections/Best%20Frens/holders", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	assert.Equal(t, 1, client.CallCount("GetAssetHoldingsByCollection"))
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	server.New(newFakeClient(), server.Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var spec struct {
+		Paths map[string]map[string]interface{}
+	}
+	assert.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &spec))
+
+	routes := map[string]string{
+		"/openapi.yaml":                 "get",
+		"/collections":                  "get",
+		"/collections/{name}/holders":   "get",
+		"/addresses/{address}/holdings": "get",
+		"/raffles":                      "post",
+		"/snapshots":                    "get",
+		"/snapshots/{name}":             "get",
+	}
+	assert.Len(t, spec.Paths, len(routes))
+	for path, method := range routes {
+		assert.Contains(t, spec.Paths[path], method, path)
+	}
+}
+
+func TestRaffleIsReproducible(t *testing.T) {
+	underTest := server.New(newFakeClient(), server.Options{Collections: weightedCollections})
+	draw := func() server.RaffleResponse {
+		recorder := httptest.NewRecorder()
+		underTest.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/raffles", strings.NewReader(`{"Seed":"same","Winners":2,"IncludeCreators":true}`)))
+		var response server.RaffleResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		return response
+	}
+
+	first := draw()
+	assert.Len(t, first.Winners, 2)
+	assert.Equal(t, first, draw())
+}
@@ -0,0 +1,158 @@
+package holders
+
+import (
+	"sort"
+	"strings"
+)
+
+// SetPiece is one requirement of a Set. A holder has the piece when they hold any matching asset.
+type SetPiece struct {
+	Name    string
+	Matches func(collectionName string, holding AssetHolding) bool
+}
+
+type Set struct {
+	Name   string
+	Pieces []SetPiece
+}
+
+// CollectionsSet requires one asset from every named collection.
+func CollectionsSet(name string, collectionNames ...string) Set {
+	set := Set{Name: name}
+	for _, collectionName := range collectionNames {
+		collectionName := collectionName
+		set.Pieces = append(set.Pieces, SetPiece{
+			Name: collectionName,
+			Matches: func(holdingCollection string, _ AssetHolding) bool {
+				return holdingCollection == collectionName
+			},
+		})
+	}
+	return set
+}
+
+// AssetsSet requires every one of the given assets.
+func AssetsSet(name string, assets []Asset) Set {
+	set := Set{Name: name}
+	for _, asset := range assets {
+		assetID := asset.AssetID
+		set.Pieces = append(set.Pieces, SetPiece{
+			Name: pieceName(asset),
+			Matches: func(_ string, holding AssetHolding) bool {
+				return holding.AssetID == assetID
+			},
+		})
+	}
+	return set
+}
+
+// UnitNamePrefixSet requires every one of the given assets whose unit name starts with prefix.
+func UnitNamePrefixSet(name string, prefix string, assets []Asset) Set {
+	var matching []Asset
+	for _, asset := range assets {
+		if strings.HasPrefix(strings.TrimSpace(asset.UnitName), strings.TrimSpace(prefix)) {
+			matching = append(matching, asset)
+		}
+	}
+	return AssetsSet(name, matching)
+}
+
+// NameContainsSet requires one asset of the collection whose name contains each of the values,
+// for example every trait value that is part of the asset name.
+func NameContainsSet(name string, collectionName string, values ...string) Set {
+	set := Set{Name: name}
+	for _, value := range values {
+		value := value
+		set.Pieces = append(set.Pieces, SetPiece{
+			Name: value,
+			Matches: func(holdingCollection string, holding AssetHolding) bool {
+				return holdingCollection == collectionName &&
+					strings.Contains(strings.ToLower(holding.Name), strings.ToLower(value))
+			},
+		})
+	}
+	return set
+}
+
+type SetProgress struct {
+	Set      string
+	Address  string
+	Held     []string
+	Missing  []string
+	Required int
+}
+
+func (p SetProgress) IsComplete() bool {
+	return len(p.Missing) == 0
+}
+
+// Completion returns the fraction of set pieces held, between 0 and 1.
+func (p SetProgress) Completion() float64 {
+	if p.Required == 0 {
+		return 1
+	}
+	return float64(len(p.Held)) / float64(p.Required)
+}
+
+// GetSetProgress reports how close each holder is to completing the set, most complete first.
+// Holders that have none of the pieces are not included.
+func GetSetProgress(holdingsByCollection map[string][]AssetHolding, set Set) []SetProgress {
+	heldPieces := make(map[string]map[int]bool)
+	for collectionName, holdings := range holdingsByCollection {
+		for _, holding := range holdings {
+			for i, piece := range set.Pieces {
+				if !piece.Matches(collectionName, holding) {
+					continue
+				}
+				if heldPieces[holding.Address] == nil {
+					heldPieces[holding.Address] = make(map[int]bool)
+				}
+				heldPieces[holding.Address][i] = true
+			}
+		}
+	}
+
+	var progress []SetProgress
+	for address, held := range heldPieces {
+		p := SetProgress{
+			Set:      set.Name,
+			Address:  address,
+			Required: len(set.Pieces),
+		}
+		for i, piece := range set.Pieces {
+			if held[i] {
+				p.Held = append(p.Held, piece.Name)
+			} else {
+				p.Missing = append(p.Missing, piece.Name)
+			}
+		}
+		progress = append(progress, p)
+	}
+
+	sort.Slice(progress, func(i, j int) bool {
+		if len(progress[i].Held) != len(progress[j].Held) {
+			return len(progress[i].Held) > len(progress[j].Held)
+		}
+		return progress[i].Address < progress[j].Address
+	})
+
+	return progress
+}
+
+// GetCompleteSetHolders returns the addresses holding every piece of the set.
+func GetCompleteSetHolders(holdingsByCollection map[string][]AssetHolding, set Set) []string {
+	var addresses []string
+	for _, p := range GetSetProgress(holdingsByCollection, set) {
+		if p.IsComplete() {
+			addresses = append(addresses, p.Address)
+		}
+	}
+	return addresses
+}
+
+func pieceName(asset Asset) string {
+	if asset.UnitName != "" {
+		return asset.UnitName
+	}
+	return asset.Name
+}
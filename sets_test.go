@@ -0,0 +1,106 @@
+package holders
+
+import (
+	"github.com/mroth/weightedrand/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var setTestHoldings = map[string][]AssetHolding{
+	"Mostly Frens": {
+		{Address: "A", Amount: 1, AssetID: 1, Name: "Fren Red", UnitName: "MFER001"},
+		{Address: "B", Amount: 1, AssetID: 2, Name: "Fren Blue", UnitName: "MFER002"},
+	},
+	"Best Frens": {
+		{Address: "A", Amount: 1, AssetID: 3, Name: "Best Fren", UnitName: "BFER001"},
+	},
+}
+
+func TestGetSetProgress(t *testing.T) {
+	tests := map[string]struct {
+		GotSet       Set
+		WantProgress []SetProgress
+	}{
+		"collections set": {
+			GotSet: CollectionsSet("Frens", "Mostly Frens", "Best Frens"),
+			WantProgress: []SetProgress{
+				{Set: "Frens", Address: "A", Held: []string{"Mostly Frens", "Best Frens"}, Required: 2},
+				{Set: "Frens", Address: "B", Held: []string{"Mostly Frens"}, Missing: []string{"Best Frens"}, Required: 2},
+			},
+		},
+		"unit name prefix set": {
+			GotSet: UnitNamePrefixSet("MFER", "MFER", []Asset{
+				{AssetID: 1, UnitName: "MFER001"},
+				{AssetID: 2, UnitName: "MFER002"},
+				{AssetID: 3, UnitName: "BFER001"},
+			}),
+			WantProgress: []SetProgress{
+				{Set: "MFER", Address: "A", Held: []string{"MFER001"}, Missing: []string{"MFER002"}, Required: 2},
+				{Set: "MFER", Address: "B", Held: []string{"MFER002"}, Missing: []string{"MFER001"}, Required: 2},
+			},
+		},
+		"name contains set": {
+			GotSet: NameContainsSet("Colours", "Mostly Frens", "Red", "Blue"),
+			WantProgress: []SetProgress{
+				{Set: "Colours", Address: "A", Held: []string{"Red"}, Missing: []string{"Blue"}, Required: 2},
+				{Set: "Colours", Address: "B", Held: []string{"Blue"}, Missing: []string{"Red"}, Required: 2},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.WantProgress, GetSetProgress(setTestHoldings, test.GotSet))
+		})
+	}
+}
+
+func TestApplySetBonuses(t *testing.T) {
+	var tickets []weightedrand.Choice[AssetHolding, uint64]
+	for _, holdings := range setTestHoldings {
+		for _, holding := range holdings {
+			tickets = append(tickets, weightedrand.Choice[AssetHolding, uint64]{Item: holding, Weight: 1})
+		}
+	}
+
+	tickets, err := applySetBonuses(tickets, setTestHoldings, []SetBonus{
+		{Set: CollectionsSet("Frens", "Mostly Frens", "Best Frens"), Multiplier: 3},
+	})
+	assert.NoError(t, err)
+
+	for _, ticket := range tickets {
+		if ticket.Item.Address == "A" {
+			assert.Equal(t, uint64(3), ticket.Weight)
+		} else {
+			assert.Equal(t, uint64(1), ticket.Weight)
+		}
+	}
+}
+
+func TestApplySetBonusesInvalid(t *testing.T) {
+	set := CollectionsSet("Frens", "Mostly Frens", "Best Frens")
+	weightedCollections := []WeightedCollection{
+		{Collection: Collection{Name: "Mostly Frens"}, Weight: 1 << 40},
+		{Collection: Collection{Name: "Best Frens"}, Weight: 1},
+	}
+
+	tests := map[string]struct {
+		GotMultiplier uint64
+		WantErr       string
+	}{
+		"zero multiplier": {
+			GotMultiplier: 0,
+			WantErr:       "set bonus Frens: multiplier must be at least 1",
+		},
+		"overflowing multiplier": {
+			GotMultiplier: 1 << 30,
+			WantErr:       "set bonus Frens: weight of asset 1 held by A overflows uint64",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := RaffleConfig{NumberOfWinners: 1, SetBonuses: []SetBonus{{Set: set, Multiplier: test.GotMultiplier}}}
+			_, err := RunWeightedRaffle(setTestHoldings, weightedCollections, config)
+			assert.EqualError(t, err, test.WantErr)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+var (
+	ErrNotSigned        = errors.New("snapshot is not signed")
+	ErrInvalidSignature = errors.New("snapshot signature is invalid")
+)
+
+// Attestation is the signature of a snapshot by an Algorand account.
+type Attestation struct {
+	// Signer is the address of the signing account.
+	Signer string
+	// Signature is the ed25519 signature of "MX" followed by the canonical encoding of the snapshot,
+	// the way Algorand accounts sign arbitrary bytes.
+	Signature []byte
+}
+
+// Sign returns the snapshot with an attestation signed by privateKey, replacing any previous one.
+func Sign(snapshot Snapshot, privateKey ed25519.PrivateKey) (Snapshot, error) {
+	address, err := crypto.GenerateAddressFromSK(privateKey)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	data, err := Canonical(snapshot)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	signature, err := crypto.SignBytes(privateKey, data)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot.Attestation = &Attestation{Signer: address.String(), Signature: signature}
+	return snapshot, nil
+}
+
+// Verify checks that the snapshot was signed by address and has not changed since. It needs no network access.
+func Verify(snapshot Snapshot, address string) error {
+	if snapshot.Attestation == nil {
+		return ErrNotSigned
+	}
+	if snapshot.Attestation.Signer != address {
+		return fmt.Errorf("snapshot is signed by %s, not %s", snapshot.Attestation.Signer, address)
+	}
+	publicKey, err := types.DecodeAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid signer address %q: %w", address, err)
+	}
+
+	data, err := Canonical(snapshot)
+	if err != nil {
+		return err
+	}
+	if !crypto.VerifyBytes(publicKey[:], data, snapshot.Attestation.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
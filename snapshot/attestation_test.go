@@ -0,0 +1,67 @@
+package snapshot_test
+
+import (
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/examples"
+	"github.com/yellowbackground/holders/snapshot"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	account := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+	unsigned := snapshot.New(map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "A", Amount: 1, AssetID: 1, UnitName: "MFER001"}},
+	}, examples.Collections, 100)
+	unsigned.Metadata["note"] = "weekly"
+
+	signed, err := snapshot.Sign(unsigned, account.PrivateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address.String(), signed.Attestation.Signer)
+
+	// verification works on the file as partners receive it
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	assert.NoError(t, snapshot.Save(path, signed))
+	loaded, err := snapshot.Load(path)
+	assert.NoError(t, err)
+	assert.NoError(t, snapshot.Verify(loaded, account.Address.String()))
+
+	tampered, _ := snapshot.Load(path)
+	tampered.Holdings["Mostly Frens"][0].Amount = 2
+	assert.ErrorIs(t, snapshot.Verify(tampered, account.Address.String()), snapshot.ErrInvalidSignature)
+
+	forged, err := snapshot.Sign(unsigned, other.PrivateKey)
+	assert.NoError(t, err)
+	forged.Attestation.Signer = account.Address.String()
+	assert.ErrorIs(t, snapshot.Verify(forged, account.Address.String()), snapshot.ErrInvalidSignature)
+
+	assert.EqualError(t, snapshot.Verify(loaded, other.Address.String()),
+		"snapshot is signed by "+account.Address.String()+", not "+other.Address.String())
+	assert.ErrorIs(t, snapshot.Verify(unsigned, account.Address.String()), snapshot.ErrNotSigned)
+}
+
+func TestCanonical(t *testing.T) {
+	snap := snapshot.Snapshot{
+		Version:         1,
+		CreatedAt:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Round:           7,
+		CollectionsHash: "h",
+		// sorted by UTF-16 code units, the emoji's surrogate pair comes before U+FB01
+		Metadata: map[string]string{"ﬁ": "1", "\U0001F600": "2", "note": "<b>\n\u0001"},
+		Holdings: map[string][]holders.AssetHolding{
+			"Frens é": {{Name: `Fren "1"`, UnitName: "F1", Address: "A", Amount: 1 << 60, AssetID: 1}},
+		},
+		Attestation: &snapshot.Attestation{Signer: "A"},
+	}
+
+	data, err := snapshot.Canonical(snap)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"CollectionsHash":"h","CreatedAt":"2024-01-02T03:04:05Z",`+
+		`"Holdings":{"Frens é":[{"Address":"A","Amount":1152921504606846976,"AssetID":1,"Decimals":0,"Name":"Fren \"1\"","UnitName":"F1"}]},`+
+		`"Metadata":{"note":"<b>\n\u0001","`+"\U0001F600"+`":"2","`+"ﬁ"+`":"1"},"Round":7,"Version":1}`, string(data))
+}
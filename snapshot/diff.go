@@ -0,0 +1,152 @@
+package snapshot
+
+import (
+	"github.com/yellowbackground/holders"
+	"sort"
+)
+
+// Diff is the change in holdings from one snapshot to another, by collection.
+type Diff struct {
+	FromRound   uint64
+	ToRound     uint64
+	Collections []CollectionDiff
+}
+
+type CollectionDiff struct {
+	Collection     string
+	NewHolders     []string
+	ExitedHolders  []string
+	BalanceChanges []BalanceChange
+	Transfers      []Transfer
+}
+
+func (d CollectionDiff) IsEmpty() bool {
+	return len(d.NewHolders) == 0 && len(d.ExitedHolders) == 0 && len(d.BalanceChanges) == 0 && len(d.Transfers) == 0
+}
+
+// BalanceChange is the change of an address's holdings in a collection, in whole units.
+type BalanceChange struct {
+	Address string
+	Before  float64
+	After   float64
+}
+
+// Transfer is inferred from one address losing and another gaining the same asset.
+// Amount is in base units.
+type Transfer struct {
+	AssetID uint64
+	From    string
+	To      string
+	Amount  uint64
+}
+
+// Compare diffs two snapshots. Collections are ordered by name and addresses alphabetically.
+func Compare(from Snapshot, to Snapshot) Diff {
+	diff := Diff{FromRound: from.Round, ToRound: to.Round}
+	for _, collectionName := range sortedKeys(from.Holdings, to.Holdings) {
+		diff.Collections = append(diff.Collections, CompareHoldings(collectionName, from.Holdings[collectionName], to.Holdings[collectionName]))
+	}
+	return diff
+}
+
+// CompareHoldings diffs the holdings of a single collection.
+func CompareHoldings(collectionName string, from []holders.AssetHolding, to []holders.AssetHolding) CollectionDiff {
+	diff := CollectionDiff{Collection: collectionName}
+
+	fromTotals, fromAssets := totals(from)
+	toTotals, toAssets := totals(to)
+
+	for _, address := range sortedKeys(fromTotals, toTotals) {
+		before, held := fromTotals[address]
+		after, holds := toTotals[address]
+		switch {
+		case !held:
+			diff.NewHolders = append(diff.NewHolders, address)
+		case !holds:
+			diff.ExitedHolders = append(diff.ExitedHolders, address)
+		}
+		if before != after {
+			diff.BalanceChanges = append(diff.BalanceChanges, BalanceChange{Address: address, Before: before, After: after})
+		}
+	}
+
+	diff.Transfers = inferTransfers(fromAssets, toAssets)
+	return diff
+}
+
+type assetBalances map[uint64]map[string]uint64
+
+func totals(holdings []holders.AssetHolding) (map[string]float64, assetBalances) {
+	totalsByAddress := make(map[string]float64)
+	balances := make(assetBalances)
+	for _, holding := range holdings {
+		totalsByAddress[holding.Address] += holding.DecimalAmount()
+		if balances[holding.AssetID] == nil {
+			balances[holding.AssetID] = make(map[string]uint64)
+		}
+		balances[holding.AssetID][holding.Address] += holding.Amount
+	}
+	return totalsByAddress, balances
+}
+
+// inferTransfers pairs the addresses that lost an asset with those that gained it, in address order.
+func inferTransfers(from assetBalances, to assetBalances) []Transfer {
+	assetIDs := make(map[uint64]bool)
+	for assetID := range from {
+		assetIDs[assetID] = true
+	}
+	for assetID := range to {
+		assetIDs[assetID] = true
+	}
+	sortedAssetIDs := make([]uint64, 0, len(assetIDs))
+	for assetID := range assetIDs {
+		sortedAssetIDs = append(sortedAssetIDs, assetID)
+	}
+	sort.Slice(sortedAssetIDs, func(i, j int) bool { return sortedAssetIDs[i] < sortedAssetIDs[j] })
+
+	var transfers []Transfer
+	for _, assetID := range sortedAssetIDs {
+		type change struct {
+			address string
+			amount  uint64
+		}
+		var senders, receivers []change
+		for _, address := range sortedKeys(from[assetID], to[assetID]) {
+			before, after := from[assetID][address], to[assetID][address]
+			if before > after {
+				senders = append(senders, change{address, before - after})
+			} else if after > before {
+				receivers = append(receivers, change{address, after - before})
+			}
+		}
+
+		for len(senders) > 0 && len(receivers) > 0 {
+			amount := min(senders[0].amount, receivers[0].amount)
+			transfers = append(transfers, Transfer{AssetID: assetID, From: senders[0].address, To: receivers[0].address, Amount: amount})
+			senders[0].amount -= amount
+			receivers[0].amount -= amount
+			if senders[0].amount == 0 {
+				senders = senders[1:]
+			}
+			if receivers[0].amount == 0 {
+				receivers = receivers[1:]
+			}
+		}
+	}
+	return transfers
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+// Package snapshot saves holdings to versioned files and diffs two snapshots.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/yellowbackground/holders"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Version is the snapshot file format version written by this package.
+const Version = 1
+
+type Snapshot struct {
+	Version         int
+	CreatedAt       time.Time
+	Round           uint64
+	CollectionsHash string
+	Metadata        map[string]string
+	Holdings        map[string][]holders.AssetHolding
+	// Attestation is set when the snapshot has been signed, see Sign.
+	Attestation *Attestation `json:",omitempty"`
+}
+
+// New creates a snapshot of holdings taken at round with the collections that produced them.
+func New(holdingsByCollection map[string][]holders.AssetHolding, collections []holders.Collection, round uint64) Snapshot {
+	return Snapshot{
+		Version:         Version,
+		CreatedAt:       time.Now().UTC(),
+		Round:           round,
+		CollectionsHash: holders.HashCollections(collections),
+		Metadata:        map[string]string{},
+		Holdings:        holdingsByCollection,
+	}
+}
+
+func Write(w io.Writer, snapshot Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+func Read(r io.Reader) (Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.Version < 1 || snapshot.Version > Version {
+		return Snapshot{}, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+func Save(path string, snapshot Snapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, snapshot); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func Load(path string) (Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer file.Close()
+
+	snapshot, err := Read(file)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// HashHoldings returns a SHA-256 of holdings that doesn't depend on the order they were fetched in,
+// so the same holdings always have the same hash whether they came from a snapshot file or the network.
+func HashHoldings(holdingsByCollection map[string][]holders.AssetHolding) (string, error) {
+	sorted := make(map[string][]holders.AssetHolding, len(holdingsByCollection))
+	for collectionName, holdings := range holdingsByCollection {
+		holdings = append([]holders.AssetHolding{}, holdings...)
+		sort.Slice(holdings, func(i, j int) bool {
+			if holdings[i].AssetID != holdings[j].AssetID {
+				return holdings[i].AssetID < holdings[j].AssetID
+			}
+			return holdings[i].Address < holdings[j].Address
+		})
+		sorted[collectionName] = holdings
+	}
+	data, err := json.Marshal(sorted)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
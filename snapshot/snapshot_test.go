@@ -0,0 +1,93 @@
+package snapshot_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/examples"
+	"github.com/yellowbackground/holders/snapshot"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	want := snapshot.New(map[string][]holders.AssetHolding{
+		"Mostly Frens": {{Address: "A", Amount: 1, AssetID: 1, UnitName: "MFER001"}},
+	}, examples.Collections, 100)
+	want.Metadata["note"] = "weekly"
+
+	assert.NoError(t, snapshot.Save(path, want))
+	got, err := snapshot.Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, holders.HashCollections(examples.Collections), got.CollectionsHash)
+	assert.NotEqual(t, holders.HashCollections(examples.Collections[:1]), got.CollectionsHash)
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	_, err := snapshot.Read(strings.NewReader(`{"Version": 99}`))
+
+	assert.EqualError(t, err, "unsupported snapshot version 99")
+}
+
+func TestCompare(t *testing.T) {
+	from := snapshot.Snapshot{Round: 100, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: "A", Amount: 1, AssetID: 1},
+			{Address: "A", Amount: 1, AssetID: 2},
+			{Address: "B", Amount: 1, AssetID: 3},
+		},
+		"Fren Token": {
+			{Address: "A", Amount: 3000000, AssetID: 9, Decimals: 6},
+		},
+	}}
+	to := snapshot.Snapshot{Round: 200, Holdings: map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: "A", Amount: 1, AssetID: 1},
+			{Address: "C", Amount: 1, AssetID: 2},
+			{Address: "C", Amount: 1, AssetID: 3},
+		},
+		"Fren Token": {
+			{Address: "A", Amount: 1000000, AssetID: 9, Decimals: 6},
+			{Address: "B", Amount: 2000000, AssetID: 9, Decimals: 6},
+		},
+	}}
+
+	diff := snapshot.Compare(from, to)
+
+	assert.Equal(t, snapshot.Diff{
+		FromRound: 100,
+		ToRound:   200,
+		Collections: []snapshot.CollectionDiff{
+			{
+				Collection:     "Fren Token",
+				NewHolders:     []string{"B"},
+				BalanceChanges: []snapshot.BalanceChange{{Address: "A", Before: 3, After: 1}, {Address: "B", Before: 0, After: 2}},
+				Transfers:      []snapshot.Transfer{{AssetID: 9, From: "A", To: "B", Amount: 2000000}},
+			},
+			{
+				Collection:     "Mostly Frens",
+				NewHolders:     []string{"C"},
+				ExitedHolders:  []string{"B"},
+				BalanceChanges: []snapshot.BalanceChange{{Address: "A", Before: 2, After: 1}, {Address: "B", Before: 1, After: 0}, {Address: "C", Before: 0, After: 2}},
+				Transfers:      []snapshot.Transfer{{AssetID: 2, From: "A", To: "C", Amount: 1}, {AssetID: 3, From: "B", To: "C", Amount: 1}},
+			},
+		},
+	}, diff)
+}
+
+func TestHashHoldings(t *testing.T) {
+	holdings := map[string][]holders.AssetHolding{"Mostly Frens": {{Address: "A", AssetID: 2, Amount: 1}, {Address: "B", AssetID: 1, Amount: 1}}}
+	reordered := map[string][]holders.AssetHolding{"Mostly Frens": {{Address: "B", AssetID: 1, Amount: 1}, {Address: "A", AssetID: 2, Amount: 1}}}
+	changed := map[string][]holders.AssetHolding{"Mostly Frens": {{Address: "B", AssetID: 1, Amount: 2}, {Address: "A", AssetID: 2, Amount: 1}}}
+
+	hash, err := snapshot.HashHoldings(holdings)
+	assert.NoError(t, err)
+
+	reorderedHash, _ := snapshot.HashHoldings(reordered)
+	changedHash, _ := snapshot.HashHoldings(changed)
+	assert.Equal(t, hash, reorderedHash)
+	assert.NotEqual(t, hash, changedHash)
+}
@@ -0,0 +1,131 @@
+package holders
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AssetSource provides the asset IDs of a collection that is not defined by creator addresses,
+// e.g. a collection minted from many wallets or a fungible community token.
+type AssetSource interface {
+	AssetIDs(ctx context.Context) ([]uint64, error)
+}
+
+// AssetSourceFunc adapts a custom provider function to an AssetSource.
+type AssetSourceFunc func(ctx context.Context) ([]uint64, error)
+
+func (f AssetSourceFunc) AssetIDs(ctx context.Context) ([]uint64, error) {
+	return f(ctx)
+}
+
+// MultiAssetSource combines the asset IDs of several sources in order.
+func MultiAssetSource(sources ...AssetSource) AssetSource {
+	return AssetSourceFunc(func(ctx context.Context) ([]uint64, error) {
+		var assetIDs []uint64
+		for _, source := range sources {
+			sourceAssetIDs, err := source.AssetIDs(ctx)
+			if err != nil {
+				return nil, err
+			}
+			assetIDs = append(assetIDs, sourceAssetIDs...)
+		}
+		return assetIDs, nil
+	})
+}
+
+// FileAssetSource reads asset IDs from a file. See ParseAssetIDs for the format.
+func FileAssetSource(path string) AssetSource {
+	return AssetSourceFunc(func(ctx context.Context) ([]uint64, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		assetIDs, err := ParseAssetIDs(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return assetIDs, nil
+	})
+}
+
+// URLAssetSource fetches asset IDs from a URL. See ParseAssetIDs for the format.
+// A nil client uses http.DefaultClient.
+func URLAssetSource(url string, client *http.Client) AssetSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return AssetSourceFunc(func(ctx context.Context) ([]uint64, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: unexpected status %s", url, res.Status)
+		}
+		assetIDs, err := ParseAssetIDs(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		return assetIDs, nil
+	})
+}
+
+// ParseAssetIDs reads asset IDs separated by new lines, commas or spaces. Text after # is ignored.
+func ParseAssetIDs(r io.Reader) ([]uint64, error) {
+	var assetIDs []uint64
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		for _, field := range fields {
+			assetID, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid asset id %q", line, field)
+			}
+			assetIDs = append(assetIDs, assetID)
+		}
+	}
+	return assetIDs, scanner.Err()
+}
+
+// ListedAssetIDs returns the explicit asset IDs of the collection followed by those from its AssetSource,
+// without duplicates.
+func (c Collection) ListedAssetIDs(ctx context.Context) ([]uint64, error) {
+	assetIDs := c.AssetIDs
+	if c.AssetSource != nil {
+		sourceAssetIDs, err := c.AssetSource.AssetIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		assetIDs = append(append([]uint64{}, assetIDs...), sourceAssetIDs...)
+	}
+
+	var listed []uint64
+	seen := make(map[uint64]bool)
+	for _, assetID := range assetIDs {
+		if !seen[assetID] {
+			seen[assetID] = true
+			listed = append(listed, assetID)
+		}
+	}
+	return listed, nil
+}
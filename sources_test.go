@@ -0,0 +1,32 @@
+package holders
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectionListedAssetIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "30, 40\n")
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "assets.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# community tokens\n20\n10 # duplicate\n"), 0644))
+
+	collection := Collection{
+		AssetIDs:    []uint64{10},
+		AssetSource: MultiAssetSource(FileAssetSource(path), URLAssetSource(server.URL, nil)),
+	}
+
+	assetIDs, err := collection.ListedAssetIDs(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{10, 20, 30, 40}, assetIDs)
+}
@@ -0,0 +1,168 @@
+package testdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+	"sync"
+)
+
+// FakeCollectionClient serves fixed assets and holdings by collection name and counts the calls made.
+type FakeCollectionClient struct {
+	Assets   map[string][]holders.Asset
+	Holdings map[string][]holders.AssetHolding
+	// AccountAssets are the asset balances returned by GetAccountAssets, by address.
+	AccountAssets map[string][]holders.AccountAsset
+	// OptedIn are the addresses IsOptedIn reports as opted in to every asset.
+	OptedIn map[string]bool
+	// AuthAddresses are the keys of rekeyed accounts, by address.
+	AuthAddresses map[string]string
+	// AssetBalances are the balances returned by GetAssetBalances, by asset ID and address.
+	AssetBalances map[uint64]map[string]uint64
+	// BalancesRounds are the rounds GetAssetBalances reports the balances of each asset were read at.
+	BalancesRounds map[uint64]uint64
+
+	mutex sync.Mutex
+	Calls map[string]int
+}
+
+func (f *FakeCollectionClient) GetAssetHoldingsByCollection(ctx context.Context, collection holders.Collection) ([]holders.AssetHolding, error) {
+	f.count("GetAssetHoldingsByCollection")
+	return f.Holdings[collection.Name], nil
+}
+
+func (f *FakeCollectionClient) GetAssetsByCollection(ctx context.Context, collection holders.Collection) ([]holders.Asset, error) {
+	f.count("GetAssetsByCollection")
+	return f.Assets[collection.Name], nil
+}
+
+func (f *FakeCollectionClient) IsAssetOwned(ctx context.Context, asset holders.Asset) (bool, error) {
+	f.count("IsAssetOwned")
+	return true, nil
+}
+
+func (f *FakeCollectionClient) GetAccountAssets(ctx context.Context, address string) ([]holders.AccountAsset, error) {
+	f.count("GetAccountAssets")
+	return f.AccountAssets[address], nil
+}
+
+func (f *FakeCollectionClient) IsOptedIn(ctx context.Context, address string, assetID uint64) (bool, error) {
+	f.count("IsOptedIn")
+	return f.OptedIn[address], nil
+}
+
+func (f *FakeCollectionClient) AuthAddress(ctx context.Context, address string) (string, error) {
+	f.count("AuthAddress")
+	if authAddress, rekeyed := f.AuthAddresses[address]; rekeyed {
+		return authAddress, nil
+	}
+	return address, nil
+}
+
+func (f *FakeCollectionClient) GetAssetBalances(ctx context.Context, assetID uint64) (map[string]uint64, uint64, error) {
+	f.count("GetAssetBalances")
+	balances := make(map[string]uint64, len(f.AssetBalances[assetID]))
+	for address, amount := range f.AssetBalances[assetID] {
+		balances[address] = amount
+	}
+	return balances, f.BalancesRounds[assetID], nil
+}
+
+func (f *FakeCollectionClient) CallCount(method string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.Calls[method]
+}
+
+func (f *FakeCollectionClient) count(method string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.Calls == nil {
+		f.Calls = make(map[string]int)
+	}
+	f.Calls[method]++
+}
+
+// FakeTransactionSender confirms every group it is sent in the round after Round, unless DropSent is set.
+type FakeTransactionSender struct {
+	Round  uint64
+	Params types.SuggestedParams
+	// Confirmed are the rounds transactions were confirmed in, by transaction ID.
+	Confirmed map[string]uint64
+	// DropSent loses sent groups, as if they never reached the network.
+	DropSent bool
+	// FailWait makes WaitForConfirmation fail, as if the process died after sending.
+	FailWait bool
+	Sent     [][]byte
+}
+
+func (f *FakeTransactionSender) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	return f.Params, nil
+}
+
+func (f *FakeTransactionSender) CurrentRound(ctx context.Context) (uint64, error) {
+	return f.Round, nil
+}
+
+func (f *FakeTransactionSender) SendTransactions(ctx context.Context, signed []byte) error {
+	f.Sent = append(f.Sent, signed)
+	if f.DropSent {
+		return nil
+	}
+	if f.Confirmed == nil {
+		f.Confirmed = make(map[string]uint64)
+	}
+	decoder := msgpack.NewDecoder(bytes.NewReader(signed))
+	for {
+		var stx types.SignedTxn
+		if err := decoder.Decode(&stx); err != nil {
+			break
+		}
+		txID := crypto.GetTxID(stx.Txn)
+		if _, found := f.Confirmed[txID]; !found {
+			f.Confirmed[txID] = f.Round + 1
+		}
+	}
+	return nil
+}
+
+func (f *FakeTransactionSender) WaitForConfirmation(ctx context.Context, txID string) (uint64, error) {
+	if f.FailWait {
+		return 0, errors.New("connection lost")
+	}
+	round, found := f.Confirmed[txID]
+	if !found {
+		return 0, fmt.Errorf("wait for transaction id %s timed out", txID)
+	}
+	return round, nil
+}
+
+func (f *FakeTransactionSender) ConfirmedRound(ctx context.Context, txID string) (uint64, error) {
+	return f.Confirmed[txID], nil
+}
+
+// FakeBlockClient serves Blocks by round. WaitForBlock waits until ctx is done for rounds it doesn't have.
+type FakeBlockClient struct {
+	Blocks map[uint64]types.Block
+}
+
+func (f *FakeBlockClient) WaitForBlock(ctx context.Context, round uint64) error {
+	if _, found := f.Blocks[round]; found {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *FakeBlockClient) Block(ctx context.Context, round uint64) (types.Block, error) {
+	block, found := f.Blocks[round]
+	if !found {
+		return types.Block{}, fmt.Errorf("block %d not found", round)
+	}
+	return block, nil
+}
@@ -0,0 +1,101 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// checkpoint is the tracker state saved between runs.
+type checkpoint struct {
+	Round uint64
+	// AssetIDs are the tracked assets, sorted, so a checkpoint of other collections isn't resumed from.
+	AssetIDs []uint64
+	// Balances are the balances of each asset by address. Asset IDs are strings since JSON keys must be.
+	Balances map[string]map[string]uint64
+}
+
+func loadCheckpoint(path string) (checkpoint, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint{}, false, nil
+	}
+	if err != nil {
+		return checkpoint{}, false, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+// matches reports whether cp was saved by a tracker of the same assets.
+func (t *Tracker) matches(cp checkpoint) bool {
+	assetIDs := t.assetIDs()
+	if len(cp.AssetIDs) != len(assetIDs) {
+		return false
+	}
+	for i, assetID := range assetIDs {
+		if cp.AssetIDs[i] != assetID {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Tracker) assetIDs() []uint64 {
+	assetIDs := make([]uint64, 0, len(t.balances))
+	for assetID := range t.balances {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
+	return assetIDs
+}
+
+// restore replaces the balances of the tracked assets with those of the checkpoint.
+func (t *Tracker) restore(cp checkpoint) {
+	t.round = cp.Round
+	for assetID := range t.balances {
+		t.balances[assetID] = make(map[string]uint64)
+		for address, amount := range cp.Balances[strconv.FormatUint(assetID, 10)] {
+			t.balances[assetID][address] = amount
+		}
+	}
+}
+
+// saveCheckpoint writes to a temporary file first so a crash never leaves a partial checkpoint behind.
+func (t *Tracker) saveCheckpoint() error {
+	t.mutex.RLock()
+	cp := checkpoint{Round: t.round, AssetIDs: t.assetIDs(), Balances: make(map[string]map[string]uint64, len(t.balances))}
+	for assetID, balances := range t.balances {
+		copied := make(map[string]uint64, len(balances))
+		for address, amount := range balances {
+			copied[address] = amount
+		}
+		cp.Balances[strconv.FormatUint(assetID, 10)] = copied
+	}
+	t.mutex.RUnlock()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.options.CheckpointPath), "checkpoint-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.options.CheckpointPath)
+}
@@ -0,0 +1,420 @@
+// Package tracker keeps the holders of collections current by following new blocks,
+// instead of scanning every collection again.
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/yellowbackground/holders"
+	"math"
+	"sort"
+	"sync"
+)
+
+const defaultCheckpointEvery = 100
+
+type EventType string
+
+const (
+	// BalanceChanged is sent for every tracked asset balance changed by a transaction.
+	BalanceChanged EventType = "balance_changed"
+	// HolderJoined is sent when an address starts holding a collection.
+	HolderJoined EventType = "holder_joined"
+	// HolderExited is sent when an address no longer holds any asset of a collection.
+	HolderExited EventType = "holder_exited"
+)
+
+// Event is a change to the holders of a collection. AssetID, Before and After are only set for BalanceChanged.
+type Event struct {
+	Type       EventType
+	Round      uint64
+	Collection string
+	Address    string
+	AssetID    uint64
+	Before     uint64
+	After      uint64
+}
+
+type Options struct {
+	// CheckpointPath is where the tracker saves its state, and resumes from instead of reading the balances again.
+	// A checkpoint of a different set of assets is ignored and overwritten.
+	CheckpointPath string
+	// CheckpointEvery is the number of rounds between checkpoints. Defaults to 100.
+	CheckpointEvery uint64
+}
+
+// Tracker keeps an index of the balances of every asset in the tracked collections. It is safe for concurrent use.
+// Assets added to a collection after the tracker was created are not tracked until it is created again.
+type Tracker struct {
+	blocks      holders.BlockClient
+	options     Options
+	collections []holders.Collection
+	// collectionsByAsset are the indexes in collections of the collections each asset belongs to.
+	collectionsByAsset map[uint64][]int
+	assetsByCollection [][]uint64
+	assets             map[uint64]holders.Asset
+
+	// applyMutex keeps blocks, and the events they send, in order
+	applyMutex  sync.Mutex
+	mutex       sync.RWMutex
+	round       uint64
+	balances    map[uint64]map[string]uint64
+	subscribers map[int]subscriber
+	nextID      int
+	// seededAt are the rounds the balances of each asset were read at, while New replays the blocks after them.
+	seededAt map[uint64]uint64
+}
+
+type subscriber struct {
+	events chan Event
+	done   chan struct{}
+}
+
+// New tracks the collections from the checkpoint, or else from the balances of their assets. The balances are
+// read unfiltered, since the collection filters only apply to holdings, and as each asset may be read at
+// a different round, the blocks in between are replayed so the tracker starts at the latest of them.
+func New(ctx context.Context, blocks holders.BlockClient, collectionClient holders.CollectionClient, balancesClient holders.AssetBalancesClient, collections []holders.Collection, options Options) (*Tracker, error) {
+	if options.CheckpointEvery == 0 {
+		options.CheckpointEvery = defaultCheckpointEvery
+	}
+	t := &Tracker{
+		blocks:             blocks,
+		options:            options,
+		collections:        collections,
+		collectionsByAsset: make(map[uint64][]int),
+		assetsByCollection: make([][]uint64, len(collections)),
+		assets:             make(map[uint64]holders.Asset),
+		balances:           make(map[uint64]map[string]uint64),
+		subscribers:        make(map[int]subscriber),
+	}
+
+	for i, collection := range collections {
+		assets, err := collectionClient.GetAssetsByCollection(ctx, collection)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", collection.Name, err)
+		}
+		for _, asset := range assets {
+			t.collectionsByAsset[asset.AssetID] = append(t.collectionsByAsset[asset.AssetID], i)
+			t.assetsByCollection[i] = append(t.assetsByCollection[i], asset.AssetID)
+			t.assets[asset.AssetID] = asset
+			t.balances[asset.AssetID] = make(map[string]uint64)
+		}
+	}
+	if len(t.assets) == 0 {
+		return nil, fmt.Errorf("no assets to track")
+	}
+
+	if options.CheckpointPath != "" {
+		checkpoint, found, err := loadCheckpoint(options.CheckpointPath)
+		if err != nil {
+			return nil, err
+		}
+		if found && t.matches(checkpoint) {
+			t.restore(checkpoint)
+			return t, nil
+		}
+	}
+	if err := t.seed(ctx, balancesClient); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tracker) seed(ctx context.Context, balancesClient holders.AssetBalancesClient) error {
+	t.seededAt = make(map[uint64]uint64, len(t.balances))
+	defer func() { t.seededAt = nil }()
+
+	first := uint64(math.MaxUint64)
+	for assetID := range t.balances {
+		balances, round, err := balancesClient.GetAssetBalances(ctx, assetID)
+		if err != nil {
+			return fmt.Errorf("asset %d: %w", assetID, err)
+		}
+		for address, amount := range balances {
+			if amount > 0 {
+				t.balances[assetID][address] = amount
+			}
+		}
+		t.seededAt[assetID] = round
+		first = min(first, round)
+		t.round = max(t.round, round)
+	}
+
+	for round := first + 1; round <= t.round; round++ {
+		block, err := t.blocks.Block(ctx, round)
+		if err != nil {
+			return err
+		}
+		touched := make(map[membership]bool)
+		for _, txn := range block.Payset {
+			t.applyTransaction(round, txn.SignedTxnWithAD, touched, nil)
+		}
+	}
+	return nil
+}
+
+// Round is the last round applied.
+func (t *Tracker) Round() uint64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.round
+}
+
+// Holdings returns the current holdings of each collection, like GetAssetHoldingsByCollection.
+func (t *Tracker) Holdings() map[string][]holders.AssetHolding {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	result := make(map[string][]holders.AssetHolding, len(t.collections))
+	for _, collection := range t.collections {
+		result[collection.Name] = []holders.AssetHolding{}
+	}
+	for assetID, balances := range t.balances {
+		for address, amount := range balances {
+			holding := t.holding(assetID, address, amount)
+			for _, i := range t.collectionsByAsset[assetID] {
+				if t.collections[i].IncludesHolding(holding) {
+					result[t.collections[i].Name] = append(result[t.collections[i].Name], holding)
+				}
+			}
+		}
+	}
+	for _, holdings := range result {
+		sort.Slice(holdings, func(i, j int) bool {
+			if holdings[i].AssetID != holdings[j].AssetID {
+				return holdings[i].AssetID < holdings[j].AssetID
+			}
+			return holdings[i].Address < holdings[j].Address
+		})
+	}
+	return result
+}
+
+// Subscribe returns a channel that receives every event from now on, and a function that ends the subscription.
+// Events are sent in order and the tracker waits for subscribers, so a subscriber that stops reading
+// without unsubscribing stalls it. The channel is not closed when unsubscribing.
+func (t *Tracker) Subscribe(buffer int) (<-chan Event, func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	id := t.nextID
+	t.nextID++
+	sub := subscriber{events: make(chan Event, buffer), done: make(chan struct{})}
+	t.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			delete(t.subscribers, id)
+			close(sub.done)
+		})
+	}
+}
+
+// Run follows new blocks until ctx is done, saving a checkpoint every CheckpointEvery rounds and when it stops.
+func (t *Tracker) Run(ctx context.Context) error {
+	err := t.run(ctx)
+	if t.options.CheckpointPath != "" {
+		if checkpointErr := t.saveCheckpoint(); checkpointErr != nil && err == nil {
+			err = checkpointErr
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (t *Tracker) run(ctx context.Context) error {
+	for {
+		next := t.Round() + 1
+		if err := t.blocks.WaitForBlock(ctx, next); err != nil {
+			return err
+		}
+		block, err := t.blocks.Block(ctx, next)
+		if err != nil {
+			return err
+		}
+		if err := t.ApplyBlock(ctx, block); err != nil {
+			return err
+		}
+		if t.options.CheckpointPath != "" && next%t.options.CheckpointEvery == 0 {
+			if err := t.saveCheckpoint(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ApplyBlock applies the asset transfers, opt-outs and closes of the tracked assets in block,
+// which must be the round after Round, and sends the resulting events.
+func (t *Tracker) ApplyBlock(ctx context.Context, block types.Block) error {
+	t.applyMutex.Lock()
+	defer t.applyMutex.Unlock()
+
+	t.mutex.Lock()
+	round := uint64(block.Round)
+	if round != t.round+1 {
+		t.mutex.Unlock()
+		return fmt.Errorf("expected block %d, got %d", t.round+1, round)
+	}
+
+	var events []Event
+	touched := make(map[membership]bool)
+	for _, txn := range block.Payset {
+		events = t.applyTransaction(round, txn.SignedTxnWithAD, touched, events)
+	}
+	events = append(events, t.membershipEvents(round, touched)...)
+	t.round = round
+
+	subscribers := make([]subscriber, 0, len(t.subscribers))
+	for _, sub := range t.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	t.mutex.Unlock()
+
+	return t.publish(ctx, subscribers, events)
+}
+
+// membership is whether an address held a collection before the block being applied.
+type membership struct {
+	collection int
+	address    string
+}
+
+func (t *Tracker) applyTransaction(round uint64, txn types.SignedTxnWithAD, touched map[membership]bool, events []Event) []Event {
+	for _, inner := range txn.EvalDelta.InnerTxns {
+		events = t.applyTransaction(round, inner, touched, events)
+	}
+
+	tx := txn.Txn
+	assetID := uint64(tx.XferAsset)
+	if tx.Type != types.AssetTransferTx || t.balances[assetID] == nil || round <= t.seededAt[assetID] {
+		return events
+	}
+
+	from := tx.Sender
+	if !tx.AssetSender.IsZero() {
+		// clawback
+		from = tx.AssetSender
+	}
+	if tx.AssetAmount > 0 {
+		events = t.move(round, assetID, from.String(), tx.AssetReceiver.String(), tx.AssetAmount, touched, events)
+	}
+	if !tx.AssetCloseTo.IsZero() {
+		// an opt-out sends everything left to the close address
+		events = t.move(round, assetID, from.String(), tx.AssetCloseTo.String(), txn.AssetClosingAmount, touched, events)
+		delete(t.balances[assetID], from.String())
+	}
+	return events
+}
+
+func (t *Tracker) move(round uint64, assetID uint64, from string, to string, amount uint64, touched map[membership]bool, events []Event) []Event {
+	if from == to || amount == 0 {
+		return events
+	}
+	events = t.setBalance(round, assetID, from, saturatingSub(t.balances[assetID][from], amount), touched, events)
+	return t.setBalance(round, assetID, to, t.balances[assetID][to]+amount, touched, events)
+}
+
+func (t *Tracker) setBalance(round uint64, assetID uint64, address string, amount uint64, touched map[membership]bool, events []Event) []Event {
+	for _, i := range t.collectionsByAsset[assetID] {
+		key := membership{collection: i, address: address}
+		if _, found := touched[key]; !found {
+			touched[key] = t.holdsCollection(i, address)
+		}
+	}
+
+	before := t.balances[assetID][address]
+	if amount == 0 {
+		delete(t.balances[assetID], address)
+	} else {
+		t.balances[assetID][address] = amount
+	}
+	for _, i := range t.collectionsByAsset[assetID] {
+		events = append(events, Event{
+			Type:       BalanceChanged,
+			Round:      round,
+			Collection: t.collections[i].Name,
+			Address:    address,
+			AssetID:    assetID,
+			Before:     before,
+			After:      amount,
+		})
+	}
+	return events
+}
+
+func (t *Tracker) membershipEvents(round uint64, touched map[membership]bool) []Event {
+	keys := make([]membership, 0, len(touched))
+	for key := range touched {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].collection != keys[j].collection {
+			return keys[i].collection < keys[j].collection
+		}
+		return keys[i].address < keys[j].address
+	})
+
+	var events []Event
+	for _, key := range keys {
+		heldBefore, holdsNow := touched[key], t.holdsCollection(key.collection, key.address)
+		if heldBefore == holdsNow {
+			continue
+		}
+		eventType := HolderJoined
+		if heldBefore {
+			eventType = HolderExited
+		}
+		events = append(events, Event{Type: eventType, Round: round, Collection: t.collections[key.collection].Name, Address: key.address})
+	}
+	return events
+}
+
+func (t *Tracker) holdsCollection(collectionIndex int, address string) bool {
+	collection := t.collections[collectionIndex]
+	for _, assetID := range t.assetsByCollection[collectionIndex] {
+		amount := t.balances[assetID][address]
+		if amount > 0 && collection.IncludesHolding(t.holding(assetID, address, amount)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tracker) holding(assetID uint64, address string, amount uint64) holders.AssetHolding {
+	asset := t.assets[assetID]
+	return holders.AssetHolding{
+		Name:     asset.Name,
+		UnitName: asset.UnitName,
+		Address:  address,
+		Amount:   amount,
+		AssetID:  assetID,
+		Decimals: asset.Decimals,
+	}
+}
+
+func (t *Tracker) publish(ctx context.Context, subscribers []subscriber, events []Event) error {
+	for _, event := range events {
+		for _, sub := range subscribers {
+			select {
+			case sub.events <- event:
+			case <-sub.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func saturatingSub(a uint64, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
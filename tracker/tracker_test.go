@@ -0,0 +1,203 @@
+package tracker_test
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/yellowbackground/holders"
+	"github.com/yellowbackground/holders/testdata"
+	"github.com/yellowbackground/holders/tracker"
+	"path/filepath"
+	"testing"
+)
+
+func testAddress(i byte) types.Address {
+	var address types.Address
+	address[0] = i
+	return address
+}
+
+var (
+	creator = testAddress(1)
+	alice   = testAddress(2)
+	bob     = testAddress(3)
+	dave    = testAddress(4)
+)
+
+func transfer(assetID uint64, from types.Address, to types.Address, amount uint64) types.SignedTxnWithAD {
+	var txn types.SignedTxnWithAD
+	txn.Txn.Type = types.AssetTransferTx
+	txn.Txn.Sender = from
+	txn.Txn.XferAsset = types.AssetIndex(assetID)
+	txn.Txn.AssetReceiver = to
+	txn.Txn.AssetAmount = amount
+	return txn
+}
+
+func block(round uint64, txns ...types.SignedTxnWithAD) types.Block {
+	var b types.Block
+	b.Round = types.Round(round)
+	for _, txn := range txns {
+		b.Payset = append(b.Payset, types.SignedTxnInBlock{SignedTxnWithAD: txn})
+	}
+	return b
+}
+
+var mostlyFrens = []holders.Asset{{AssetID: 1, UnitName: "MFER001"}, {AssetID: 2, UnitName: "MFER002"}}
+
+func newTracker(t *testing.T, blocks holders.BlockClient, checkpointPath string) *tracker.Tracker {
+	return newTrackerOf(t, blocks, checkpointPath, mostlyFrens)
+}
+
+func newTrackerOf(t *testing.T, blocks holders.BlockClient, checkpointPath string, assets []holders.Asset) *tracker.Tracker {
+	client := &testdata.FakeCollectionClient{
+		Assets:         map[string][]holders.Asset{"Mostly Frens": assets},
+		AssetBalances:  map[uint64]map[string]uint64{1: {alice.String(): 1}, 2: {creator.String(): 1}},
+		BalancesRounds: map[uint64]uint64{},
+	}
+	for _, asset := range assets {
+		client.BalancesRounds[asset.AssetID] = 100
+	}
+	collections := []holders.Collection{{Name: "Mostly Frens", ExcludedHolderAddresses: []string{creator.String()}}}
+
+	underTest, err := tracker.New(context.Background(), blocks, client, client, collections, tracker.Options{CheckpointPath: checkpointPath, CheckpointEvery: 1})
+	assert.NoError(t, err)
+	return underTest
+}
+
+func TestTracker(t *testing.T) {
+	// the creator sends asset 2 to bob
+	sale := transfer(2, creator, bob, 1)
+	// alice opts out of asset 1, closing it to bob
+	optOut := transfer(1, alice, alice, 0)
+	optOut.Txn.AssetCloseTo = bob
+	optOut.AssetClosingAmount = 1
+	// an application claws asset 1 back from bob and sends it to dave
+	var appCall types.SignedTxnWithAD
+	appCall.Txn.Type = types.ApplicationCallTx
+	clawback := transfer(1, creator, dave, 1)
+	clawback.Txn.AssetSender = bob
+	appCall.EvalDelta.InnerTxns = []types.SignedTxnWithAD{clawback}
+
+	blocks := &testdata.FakeBlockClient{Blocks: map[uint64]types.Block{
+		101: block(101, sale, transfer(99, alice, bob, 5)),
+		102: block(102, optOut),
+		103: block(103, appCall),
+	}}
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+	underTest := newTracker(t, blocks, checkpointPath)
+	events, unsubscribe := underTest.Subscribe(100)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- underTest.Run(ctx)
+	}()
+
+	var got []tracker.Event
+	for event := range events {
+		if event.Type != tracker.BalanceChanged {
+			got = append(got, event)
+		}
+		if event.Round == 103 && event.Type == tracker.HolderJoined {
+			break
+		}
+	}
+	cancel()
+	assert.NoError(t, <-done)
+
+	assert.Equal(t, []tracker.Event{
+		{Type: tracker.HolderJoined, Round: 101, Collection: "Mostly Frens", Address: bob.String()},
+		{Type: tracker.HolderExited, Round: 102, Collection: "Mostly Frens", Address: alice.String()},
+		{Type: tracker.HolderJoined, Round: 103, Collection: "Mostly Frens", Address: dave.String()},
+	}, got)
+	want := map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: dave.String(), Amount: 1, AssetID: 1, UnitName: "MFER001"},
+			{Address: bob.String(), Amount: 1, AssetID: 2, UnitName: "MFER002"},
+		},
+	}
+	assert.Equal(t, uint64(103), underTest.Round())
+	assert.Equal(t, want, underTest.Holdings())
+
+	// a new tracker resumes from the checkpoint instead of reading the balances again
+	resumed := newTracker(t, blocks, checkpointPath)
+	assert.Equal(t, uint64(103), resumed.Round())
+	assert.Equal(t, want, resumed.Holdings())
+
+	// but not when the collection has other assets, whose balances the checkpoint doesn't have
+	changed := newTrackerOf(t, blocks, checkpointPath, append([]holders.Asset{{AssetID: 3, UnitName: "MFER003"}}, mostlyFrens...))
+	assert.Equal(t, uint64(100), changed.Round())
+}
+
+func TestApplyBlockOutOfOrder(t *testing.T) {
+	underTest := newTracker(t, &testdata.FakeBlockClient{}, "")
+
+	err := underTest.ApplyBlock(context.Background(), block(105))
+
+	assert.EqualError(t, err, "expected block 101, got 105")
+}
+
+func TestBalanceChangedEvents(t *testing.T) {
+	underTest := newTracker(t, &testdata.FakeBlockClient{}, "")
+	events, unsubscribe := underTest.Subscribe(10)
+	defer unsubscribe()
+
+	assert.NoError(t, underTest.ApplyBlock(context.Background(), block(101, transfer(1, alice, bob, 1))))
+
+	assert.Equal(t, tracker.Event{Type: tracker.BalanceChanged, Round: 101, Collection: "Mostly Frens", Address: alice.String(), AssetID: 1, Before: 1, After: 0}, <-events)
+	assert.Equal(t, tracker.Event{Type: tracker.BalanceChanged, Round: 101, Collection: "Mostly Frens", Address: bob.String(), AssetID: 1, Before: 0, After: 1}, <-events)
+	assert.Equal(t, tracker.Event{Type: tracker.HolderExited, Round: 101, Collection: "Mostly Frens", Address: alice.String()}, <-events)
+	assert.Equal(t, tracker.Event{Type: tracker.HolderJoined, Round: 101, Collection: "Mostly Frens", Address: bob.String()}, <-events)
+}
+
+func TestNewReplaysBlocksAfterEachAssetWasRead(t *testing.T) {
+	// asset 1 is read before both blocks and asset 2 after them, so only the sale of asset 1 is replayed
+	blocks := &testdata.FakeBlockClient{Blocks: map[uint64]types.Block{
+		101: block(101, transfer(1, alice, dave, 1), transfer(2, creator, bob, 1)),
+		102: block(102),
+	}}
+	client := &testdata.FakeCollectionClient{
+		Assets:         map[string][]holders.Asset{"Mostly Frens": mostlyFrens},
+		AssetBalances:  map[uint64]map[string]uint64{1: {alice.String(): 1}, 2: {bob.String(): 1}},
+		BalancesRounds: map[uint64]uint64{1: 100, 2: 102},
+	}
+	collections := []holders.Collection{{Name: "Mostly Frens"}}
+
+	underTest, err := tracker.New(context.Background(), blocks, client, client, collections, tracker.Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(102), underTest.Round())
+	assert.Equal(t, map[string][]holders.AssetHolding{
+		"Mostly Frens": {
+			{Address: dave.String(), Amount: 1, AssetID: 1, UnitName: "MFER001"},
+			{Address: bob.String(), Amount: 1, AssetID: 2, UnitName: "MFER002"},
+		},
+	}, underTest.Holdings())
+}
+
+func TestHolderStartingBelowMinimumBalance(t *testing.T) {
+	fungible := []holders.Asset{{AssetID: 1, UnitName: "FREN", Decimals: 1}}
+	client := &testdata.FakeCollectionClient{
+		Assets:         map[string][]holders.Asset{"Frens": fungible},
+		AssetBalances:  map[uint64]map[string]uint64{1: {alice.String(): 5, creator.String(): 100}},
+		BalancesRounds: map[uint64]uint64{1: 100},
+	}
+	collections := []holders.Collection{{Name: "Frens", MinimumBalance: 1}}
+	underTest, err := tracker.New(context.Background(), &testdata.FakeBlockClient{}, client, client, collections, tracker.Options{})
+	assert.NoError(t, err)
+	events, unsubscribe := underTest.Subscribe(10)
+	defer unsubscribe()
+
+	// alice holds 0.5, then receives 0.6
+	assert.NoError(t, underTest.ApplyBlock(context.Background(), block(101, transfer(1, creator, alice, 6))))
+
+	assert.Equal(t, tracker.Event{Type: tracker.BalanceChanged, Round: 101, Collection: "Frens", Address: creator.String(), AssetID: 1, Before: 100, After: 94}, <-events)
+	assert.Equal(t, tracker.Event{Type: tracker.BalanceChanged, Round: 101, Collection: "Frens", Address: alice.String(), AssetID: 1, Before: 5, After: 11}, <-events)
+	assert.Equal(t, tracker.Event{Type: tracker.HolderJoined, Round: 101, Collection: "Frens", Address: alice.String()}, <-events)
+	assert.Equal(t, []holders.AssetHolding{
+		{Address: creator.String(), Amount: 94, AssetID: 1, UnitName: "FREN", Decimals: 1},
+		{Address: alice.String(), Amount: 11, AssetID: 1, UnitName: "FREN", Decimals: 1},
+	}, underTest.Holdings()["Frens"])
+}
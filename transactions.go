@@ -0,0 +1,20 @@
+package holders
+
+import (
+	"context"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// TransactionSender submits signed transactions and tracks their confirmation.
+type TransactionSender interface {
+	SuggestedParams(ctx context.Context) (types.SuggestedParams, error)
+	// CurrentRound is the latest round ConfirmedRound knows about. A transaction that is not confirmed
+	// by then and whose last valid round is earlier can never be confirmed.
+	CurrentRound(ctx context.Context) (uint64, error)
+	// SendTransactions submits signed transactions, concatenated in group order.
+	SendTransactions(ctx context.Context, signed []byte) error
+	// WaitForConfirmation returns the round the transaction was confirmed in.
+	WaitForConfirmation(ctx context.Context, txID string) (uint64, error)
+	// ConfirmedRound returns the round the transaction was confirmed in, or 0 if it has not been confirmed.
+	ConfirmedRound(ctx context.Context, txID string) (uint64, error)
+}
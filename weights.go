@@ -0,0 +1,163 @@
+package holders
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultAssetWeight = 1
+
+// AssetWeigher returns the weight of a single asset within a weighted collection.
+// The asset weight is multiplied with the collection weight and the amount held.
+type AssetWeigher interface {
+	AssetWeight(asset Asset) uint64
+}
+
+// AssetWeightFunc adapts any function of Asset to an AssetWeigher.
+type AssetWeightFunc func(asset Asset) uint64
+
+func (f AssetWeightFunc) AssetWeight(asset Asset) uint64 {
+	return f(asset)
+}
+
+// AssetWeightMap weights assets by ID. Assets missing from the map have a weight of 1.
+type AssetWeightMap map[uint64]uint64
+
+func (m AssetWeightMap) AssetWeight(asset Asset) uint64 {
+	if weight, found := m[asset.AssetID]; found {
+		return weight
+	}
+	return defaultAssetWeight
+}
+
+// AssetWeightRule matches assets whose name contains NameContains, ignoring case, whose unit name starts with
+// UnitNamePrefix and whose ID is one of AssetIDs. Empty fields match every asset.
+type AssetWeightRule struct {
+	NameContains   string
+	UnitNamePrefix string
+	AssetIDs       []uint64
+	Weight         uint64
+}
+
+func (r AssetWeightRule) matches(asset Asset) bool {
+	if r.NameContains != "" && !strings.Contains(strings.ToLower(asset.Name), strings.ToLower(r.NameContains)) {
+		return false
+	}
+	if r.UnitNamePrefix != "" && !strings.HasPrefix(strings.TrimSpace(asset.UnitName), strings.TrimSpace(r.UnitNamePrefix)) {
+		return false
+	}
+	if len(r.AssetIDs) > 0 && !containsUint64(r.AssetIDs, asset.AssetID) {
+		return false
+	}
+	return true
+}
+
+// AssetWeightRules is a rule table where the first matching rule decides the weight.
+// Assets that match no rule have a weight of 1.
+type AssetWeightRules []AssetWeightRule
+
+func (rules AssetWeightRules) AssetWeight(asset Asset) uint64 {
+	for _, rule := range rules {
+		if rule.matches(asset) {
+			return rule.Weight
+		}
+	}
+	return defaultAssetWeight
+}
+
+// AmountWeigher converts the amount held into a number of tickets. It lets fungible tokens,
+// where amounts are in base units, be combined fairly with NFTs in the same raffle.
+type AmountWeigher interface {
+	AmountWeight(holding AssetHolding) uint64
+}
+
+type AmountWeightFunc func(holding AssetHolding) uint64
+
+func (f AmountWeightFunc) AmountWeight(holding AssetHolding) uint64 {
+	return f(holding)
+}
+
+// RawAmount weights by base units held. It is the default and suits NFTs.
+var RawAmount AmountWeigher = AmountWeightFunc(func(holding AssetHolding) uint64 {
+	return holding.Amount
+})
+
+// PerWholeToken weights by whole tokens held, ignoring fractions.
+var PerWholeToken AmountWeigher = AmountWeightFunc(func(holding AssetHolding) uint64 {
+	return uint64(holding.DecimalAmount())
+})
+
+// LogScaled weights by log2(1 + whole tokens held), so doubling a balance adds one ticket.
+var LogScaled AmountWeigher = AmountWeightFunc(func(holding AssetHolding) uint64 {
+	return uint64(math.Log2(1 + holding.DecimalAmount()))
+})
+
+// PerBracket weights by the number of complete brackets of bracketSize whole tokens held.
+func PerBracket(bracketSize float64) AmountWeigher {
+	return AmountWeightFunc(func(holding AssetHolding) uint64 {
+		if bracketSize <= 0 {
+			return 0
+		}
+		return uint64(holding.DecimalAmount() / bracketSize)
+	})
+}
+
+// LoadAssetWeightsCSV reads "asset_id,weight" rows. A header row is skipped if present.
+func LoadAssetWeightsCSV(r io.Reader) (AssetWeightMap, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	weights := AssetWeightMap{}
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		assetID, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			if first {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid asset id %q", line, record[0])
+		}
+		weight, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid weight %q", line, record[1])
+		}
+		weights[assetID] = weight
+	}
+
+	return weights, nil
+}
+
+// LoadAssetWeightsCSVFile reads asset weights from a CSV file. See LoadAssetWeightsCSV.
+func LoadAssetWeightsCSVFile(fileName string) (AssetWeightMap, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadAssetWeightsCSV(file)
+}
+
+func containsUint64(values []uint64, value uint64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package holders
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCreateWeightedLotteryTicketsWithAssetWeights(t *testing.T) {
+	legendary := AssetHolding{Address: "A", Amount: 1, AssetID: 1, Name: "Legendary #1", UnitName: "LEG1"}
+	common := AssetHolding{Address: "B", Amount: 2, AssetID: 2, Name: "Common #2", UnitName: "COM2"}
+
+	tests := map[string]struct {
+		GotAssetWeights AssetWeigher
+		WantWeights     map[uint64]uint64
+	}{
+		"no asset weights": {
+			GotAssetWeights: nil,
+			WantWeights:     map[uint64]uint64{1: 3, 2: 6},
+		},
+		"asset weight map": {
+			GotAssetWeights: AssetWeightMap{1: 10},
+			WantWeights:     map[uint64]uint64{1: 30, 2: 6},
+		},
+		"asset weight rules": {
+			GotAssetWeights: AssetWeightRules{
+				{NameContains: "legendary", Weight: 5},
+				{UnitNamePrefix: "COM", Weight: 2},
+			},
+			WantWeights: map[uint64]uint64{1: 15, 2: 12},
+		},
+		"asset weight func": {
+			GotAssetWeights: AssetWeightFunc(func(asset Asset) uint64 { return asset.AssetID * 100 }),
+			WantWeights:     map[uint64]uint64{1: 300, 2: 1200},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			weightedCollections := []WeightedCollection{
+				{Collection: Collection{Name: "C"}, Weight: 3, AssetWeights: test.GotAssetWeights},
+			}
+
+			tickets, err := createWeightedLotteryTickets(map[string][]AssetHolding{"C": {legendary, common}}, weightedCollections)
+			assert.NoError(t, err)
+
+			gotWeights := map[uint64]uint64{}
+			for _, ticket := range tickets {
+				gotWeights[ticket.Item.AssetID] = ticket.Weight
+			}
+			assert.Equal(t, test.WantWeights, gotWeights)
+		})
+	}
+}
+
+func TestLoadAssetWeightsCSV(t *testing.T) {
+	weights, err := LoadAssetWeightsCSV(strings.NewReader("asset_id,weight\n1, 10\n# comment\n2,0\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, AssetWeightMap{1: 10, 2: 0}, weights)
+	assert.Equal(t, uint64(1), weights.AssetWeight(Asset{AssetID: 3}))
+
+	_, err = LoadAssetWeightsCSV(strings.NewReader("1,10\n2,x\n"))
+	assert.EqualError(t, err, `line 2: invalid weight "x"`)
+}
+
+func TestAmountWeighers(t *testing.T) {
+	token := AssetHolding{Amount: 2500000000, Decimals: 6}
+	nft := AssetHolding{Amount: 1}
+
+	tests := map[string]struct {
+		GotAmountWeigher AmountWeigher
+		WantToken        uint64
+		WantNFT          uint64
+	}{
+		"raw amount": {
+			GotAmountWeigher: RawAmount,
+			WantToken:        2500000000,
+			WantNFT:          1,
+		},
+		"per whole token": {
+			GotAmountWeigher: PerWholeToken,
+			WantToken:        2500,
+			WantNFT:          1,
+		},
+		"per bracket": {
+			GotAmountWeigher: PerBracket(1000),
+			WantToken:        2,
+			WantNFT:          0,
+		},
+		"log scaled": {
+			GotAmountWeigher: LogScaled,
+			WantToken:        11,
+			WantNFT:          1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.WantToken, test.GotAmountWeigher.AmountWeight(token))
+			assert.Equal(t, test.WantNFT, test.GotAmountWeigher.AmountWeight(nft))
+		})
+	}
+}
+
+func TestHoldingWeightOverflow(t *testing.T) {
+	weightedCollection := WeightedCollection{Collection: Collection{Name: "C"}, Weight: 1 << 40, AssetWeights: AssetWeightMap{1: 1 << 30}}
+
+	weight, err := weightedCollection.HoldingWeight(AssetHolding{Address: "A", Amount: 1, AssetID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<40), weight)
+
+	_, err = weightedCollection.HoldingWeight(AssetHolding{Address: "A", Amount: 1, AssetID: 1})
+	assert.EqualError(t, err, "C: weight of asset 1 held by A overflows uint64")
+
+	_, err = RunWeightedRaffle(map[string][]AssetHolding{"C": {{Address: "A", Amount: 1 << 30, AssetID: 2}}}, []WeightedCollection{weightedCollection}, RaffleConfig{NumberOfWinners: 1})
+	assert.EqualError(t, err, "C: weight of asset 2 held by A overflows uint64")
+}